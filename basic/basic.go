@@ -1,7 +1,14 @@
 package basic
 
 import (
+	"container/heap"
+	"container/list"
+	"hash/fnv"
+	"maps"
+	"math"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hugocarreira/easycache/engine"
@@ -11,76 +18,528 @@ import (
 //
 // Unlike FIFO, LRU, or LFU caches, Basic does not implement any eviction
 // policy based on usage patterns. Items are only removed when they expire
-// based on their TTL (Time-To-Live). If no TTL is set, items remain in the cache indefinitely.
+// based on their TTL (Time-To-Live). If no TTL is set (TTL of 0), items remain in the cache indefinitely.
 //
 // This cache is useful for scenarios where automatic expiration is needed
 // but eviction based on frequency or recency of access is not required.
 type Basic struct {
-	data            map[string]*cacheItem
-	lock            sync.RWMutex
-	maxSize         int
-	ttl             time.Duration
-	cleanupInterval time.Duration
+	data         map[string]*cacheItem
+	lock         sync.RWMutex
+	maxSize      int
+	ttl          time.Duration
+	onExpire     func(key string, value any)
+	clock        func() time.Time
+	deleteOnRead bool
+	maxIdle      time.Duration
+	rand         func() uint64
+
+	// stale holds the items removed on expiration for staleGrace, so that
+	// GetStale can still serve them. They are dropped by the cleanup.
+	stale      map[string]*cacheItem
+	staleGrace time.Duration
+
+	// buckets partitions the keys so that each cleanup tick only sweeps one
+	// bucket, bounding how long the lock is held. It is nil when every tick
+	// sweeps the whole cache.
+	buckets []map[string]*cacheItem
+	cursor  int
+
+	// capacity is the number of items the key index was sized for: the peak
+	// item count since the index was last allocated. Go maps do not shrink,
+	// so the index keeps the memory of its peak until rebuilt.
+	capacity   int
+	autoShrink bool
+
+	// seq numbers the items in insertion order, to break ties between items
+	// expiring at the same time deterministically.
+	seq uint64
+
+	// expiries holds the items that expire, by TTL or idle time, ordered by
+	// deadline, so that Len and Cleanup only visit the expired ones, and Evict
+	// the one due first.
+	expiries expiryQueue
+
+	// eternal holds the items that never expire, in insertion order, so that
+	// Evict falls back to the oldest of them once no item expires.
+	eternal *list.List
+
+	// cleanupInterval is the current interval between two cleanups, in
+	// nanoseconds. It stays between minCleanupInterval and maxCleanupInterval,
+	// adapting to the number of items each cleanup removes.
+	cleanupInterval    atomic.Int64
+	minCleanupInterval time.Duration
+	maxCleanupInterval time.Duration
+
+	// done stops the cleanup goroutine once closed.
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
+var (
+	_ engine.Engine           = (*Basic)(nil)
+	_ engine.Closer           = (*Basic)(nil)
+	_ engine.Reserver         = (*Basic)(nil)
+	_ engine.ExpiryReporter   = (*Basic)(nil)
+	_ engine.StaleReader      = (*Basic)(nil)
+	_ engine.SelectiveEvicter = (*Basic)(nil)
+)
+
+// Options defines the settings used to build a Basic cache.
+type Options struct {
+	// MaxSize is the maximum number of items the cache can hold.
+	MaxSize int
+
+	// TTL is the default lifetime of items stored with Set.
+	TTL time.Duration
+
+	// CleanupInterval defines how often expired items are removed.
+	CleanupInterval time.Duration
+
+	// MinCleanupInterval and MaxCleanupInterval make the cleanup interval
+	// adaptive: it is halved, down to MinCleanupInterval, after a cleanup that
+	// finds many expired items, and doubled, up to MaxCleanupInterval, after
+	// one that finds none. A bound left at 0 is CleanupInterval, so the
+	// interval is fixed when neither is set.
+	MinCleanupInterval time.Duration
+	MaxCleanupInterval time.Duration
+
+	// OnExpire, if set, is called for every expired item removed from the cache.
+	// It is called without holding the cache lock.
+	OnExpire func(key string, value any)
+
+	// Clock, if set, replaces time.Now as the source of the current time.
+	Clock func() time.Time
+
+	// CleanupBatchFraction is the fraction of the keys swept per cleanup tick,
+	// between 0 and 1. The keys are divided into 1/CleanupBatchFraction buckets
+	// swept in turn. A value of 0 or 1 sweeps the whole cache on every tick.
+	CleanupBatchFraction float64
+
+	// DeleteOnRead makes Get remove an expired item right away, which takes the
+	// write lock on the read path. By default, Get only reports a miss and the
+	// item is removed by the next cleanup.
+	DeleteOnRead bool
+
+	// MaxIdle, if set, expires items that have not been read or written for
+	// longer than MaxIdle, in addition to their expiration time.
+	MaxIdle time.Duration
+
+	// AutoShrink makes the cleanup rebuild the key index into a smaller map
+	// once the item count falls below 1/shrinkFactor of its capacity, releasing
+	// the memory of a past peak.
+	AutoShrink bool
+
+	// StaleGrace keeps expired items for this long after their expiration, so
+	// GetStale can still return them. Other reads treat them as expired.
+	StaleGrace time.Duration
+
+	// Rand, if set, returns the random numbers that break ties between items
+	// expiring at the same time, so that they are evicted in a random order
+	// instead of by insertion. Seeding its source makes that order
+	// reproducible. It is called under the write lock.
+	Rand func() uint64
+}
+
+// shrinkFactor is the ratio between the capacity of the key index and the
+// item count below which AutoShrink rebuilds it.
+const shrinkFactor = 4
+
+// burstRatio defines a cleanup that found many expired items: one removing at
+// least 1/burstRatio of the items it swept, which shortens the next interval.
+const burstRatio = 4
+
+// minShrinkCapacity is the capacity under which the key index is never
+// rebuilt, as the memory to reclaim is not worth the copy.
+const minShrinkCapacity = 1024
+
 type cacheItem struct {
-	key       string
-	value     any
+	key   string
+	value any
+
+	// expiresAt is the expiration time of the item. The zero time means the
+	// item never expires.
 	expiresAt time.Time
+
+	// seq is the insertion order of the item. It is renewed when the item
+	// stops expiring, as it then moves to the back of the eternal list.
+	seq uint64
+
+	// tiebreak orders the item among the ones expiring at the same time,
+	// before seq. It is drawn from Options.Rand, and 0 without it.
+	tiebreak uint64
+
+	// lastAccess is the time of the last Get or Set, in Unix nanoseconds. It is
+	// updated atomically since Get only holds the read lock.
+	lastAccess atomic.Int64
+
+	// due is the deadline of the item when it was last scheduled, and index
+	// its position in the expiry queue, or -1 if it never expires.
+	due   time.Time
+	index int
+
+	// elem is the element of the item in the eternal list, or nil if it
+	// expires.
+	elem *list.Element
+}
+
+// expired reports whether the item has expired at the given time.
+func (i *cacheItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// expired reports whether the item has expired at the given time, either
+// because its expiration time has passed or because it has been idle for
+// longer than MaxIdle.
+func (c *Basic) expired(item *cacheItem, now time.Time) bool {
+	if item.expired(now) {
+		return true
+	}
+
+	return c.maxIdle > 0 && now.Sub(time.Unix(0, item.lastAccess.Load())) > c.maxIdle
+}
+
+// deadline returns the time at which the item expires, by TTL or idle time,
+// or the zero time if it never expires.
+func (c *Basic) deadline(item *cacheItem) time.Time {
+	deadline := item.expiresAt
+	if c.maxIdle > 0 {
+		idle := time.Unix(0, item.lastAccess.Load()).Add(c.maxIdle)
+		if deadline.IsZero() || idle.Before(deadline) {
+			deadline = idle
+		}
+	}
+
+	return deadline
+}
+
+// expiresBefore reports whether item expires before other, by TTL or idle
+// time. Items that never expire come last, in insertion order, and items
+// expiring at the same time are ordered by tiedBefore, so the eviction order
+// doesn't depend on the map iteration order.
+func (c *Basic) expiresBefore(item, other *cacheItem) bool {
+	deadline, otherDeadline := c.deadline(item), c.deadline(other)
+
+	switch {
+	case deadline.IsZero() && otherDeadline.IsZero():
+		return item.seq < other.seq
+	case deadline.Equal(otherDeadline):
+		return item.tiedBefore(other)
+	case deadline.IsZero():
+		return false
+	case otherDeadline.IsZero():
+		return true
+	default:
+		return deadline.Before(otherDeadline)
+	}
+}
+
+// tiedBefore reports whether the item goes before other among items expiring
+// at the same time: by tiebreak, then by insertion.
+func (i *cacheItem) tiedBefore(other *cacheItem) bool {
+	if i.tiebreak != other.tiebreak {
+		return i.tiebreak < other.tiebreak
+	}
+
+	return i.seq < other.seq
+}
+
+// itemPool recycles cacheItem structs released on Delete and Evict,
+// reducing allocations under high churn.
+var itemPool = sync.Pool{
+	New: func() any {
+		return new(cacheItem)
+	},
+}
+
+// newItem returns a cacheItem from the pool.
+func newItem(key string, value any) *cacheItem {
+	item := itemPool.Get().(*cacheItem)
+	item.key = key
+	item.value = value
+	item.index = -1
+	return item
+}
+
+// releaseItem resets an item and returns it to the pool. The item must no
+// longer be referenced by the cache.
+func releaseItem(item *cacheItem) {
+	*item = cacheItem{}
+	itemPool.Put(item)
 }
 
 func New(maxSize int, ttl, cleanupInterval time.Duration) engine.Engine {
+	return NewWithOptions(Options{
+		MaxSize:         maxSize,
+		TTL:             ttl,
+		CleanupInterval: cleanupInterval,
+	})
+}
+
+// NewWithOptions creates a Basic cache with the given options.
+func NewWithOptions(opts Options) engine.Engine {
 	c := &Basic{
-		data:            make(map[string]*cacheItem),
-		maxSize:         maxSize,
-		ttl:             ttl,
-		cleanupInterval: cleanupInterval,
+		data:               make(map[string]*cacheItem),
+		eternal:            list.New(),
+		maxSize:            opts.MaxSize,
+		ttl:                opts.TTL,
+		onExpire:           opts.OnExpire,
+		clock:              opts.Clock,
+		deleteOnRead:       opts.DeleteOnRead,
+		rand:               opts.Rand,
+		maxIdle:            opts.MaxIdle,
+		autoShrink:         opts.AutoShrink,
+		staleGrace:         opts.StaleGrace,
+		minCleanupInterval: opts.MinCleanupInterval,
+		maxCleanupInterval: opts.MaxCleanupInterval,
+		done:               make(chan struct{}),
+	}
+
+	if c.minCleanupInterval <= 0 {
+		c.minCleanupInterval = opts.CleanupInterval
+	}
+	if c.maxCleanupInterval <= 0 {
+		c.maxCleanupInterval = opts.CleanupInterval
+	}
+	c.setCleanupInterval(opts.CleanupInterval)
+
+	if opts.CleanupBatchFraction > 0 && opts.CleanupBatchFraction < 1 {
+		c.buckets = make([]map[string]*cacheItem, int(math.Ceil(1/opts.CleanupBatchFraction)))
+		for i := range c.buckets {
+			c.buckets[i] = make(map[string]*cacheItem)
+		}
 	}
 
 	go c.startCleanup()
 	return c
 }
 
-func (c *Basic) Get(key string) (any, bool) {
+// now returns the current time from the configured clock.
+func (c *Basic) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	return time.Now()
+}
+
+// store adds a new item to the cache. The caller must hold the write lock.
+func (c *Basic) store(item *cacheItem) {
+	c.data[item.key] = item
+	if c.buckets != nil {
+		c.buckets[c.bucketOf(item.key)][item.key] = item
+	}
+	c.capacity = max(c.capacity, len(c.data))
+}
+
+// Capacity returns the number of items the key index is sized for, which is
+// the peak item count since it was last allocated. With Options.AutoShrink,
+// it drops back to the item count when the cleanup shrinks the index.
+func (c *Basic) Capacity() int {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
+	return c.capacity
+}
+
+// shrink rebuilds the key index into a map sized for the current items if
+// AutoShrink is set and the index is mostly empty. The caller must hold the
+// write lock.
+func (c *Basic) shrink() {
+	if !c.autoShrink || c.capacity < minShrinkCapacity || len(c.data)*shrinkFactor > c.capacity {
+		return
+	}
+
+	c.resize(len(c.data))
+}
+
+// resize reallocates the key index, and the cleanup buckets, for n items.
+// The caller must hold the write lock.
+func (c *Basic) resize(n int) {
+	data := make(map[string]*cacheItem, n)
+	maps.Copy(data, c.data)
+	c.data = data
+
+	for i, bucket := range c.buckets {
+		resized := make(map[string]*cacheItem, n/len(c.buckets)+1)
+		maps.Copy(resized, bucket)
+		c.buckets[i] = resized
+	}
+
+	c.capacity = max(n, len(c.data))
+}
+
+// remove deletes an item from the cache. The caller must hold the write lock.
+func (c *Basic) remove(item *cacheItem) {
+	delete(c.data, item.key)
+	if c.buckets != nil {
+		delete(c.buckets[c.bucketOf(item.key)], item.key)
+	}
+	c.unschedule(item)
+}
+
+// bucketOf returns the cleanup bucket of a key.
+func (c *Basic) bucketOf(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(c.buckets)))
+}
+
+func (c *Basic) Get(key string) (any, bool) {
+	value, found, _ := c.GetOrExpired(key)
+	return value, found
+}
+
+func (c *Basic) GetOrExpired(key string) (any, bool, bool) {
+	c.lock.RLock()
+
 	item, exists := c.data[key]
-	if !exists || time.Now().After(item.expiresAt) {
-		delete(c.data, key)
-		return nil, false
+	if !exists {
+		c.lock.RUnlock()
+		return nil, false, false
+	}
+
+	now := c.now()
+	if c.expired(item, now) {
+		c.lock.RUnlock()
+		if c.deleteOnRead {
+			c.deleteExpired(key)
+		}
+		return nil, false, true
 	}
 
-	return item.value, true
+	item.lastAccess.Store(now.UnixNano())
+	value := item.value
+	c.lock.RUnlock()
+	return value, true, false
 }
 
-func (c *Basic) Set(key string, value any) {
+// deleteExpired removes key if it is still expired once the write lock is held.
+func (c *Basic) deleteExpired(key string) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
+	item, exists := c.data[key]
+	if !exists || !c.expired(item, c.now()) {
+		c.lock.Unlock()
+		return
+	}
+	c.retire(item)
+	c.lock.Unlock()
+
+	c.notifyExpired(item)
+}
+
+// GetStale returns the value of key like Get, and also the value of an item
+// that expired less than StaleGrace ago, with stale set. It does not count as
+// an access for MaxIdle.
+func (c *Basic) GetStale(key string) (any, bool, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	now := c.now()
+	item, exists := c.data[key]
+	if exists && !c.expired(item, now) {
+		item.lastAccess.Store(now.UnixNano())
+		return item.value, false, true
+	}
+
+	if !exists {
+		item, exists = c.stale[key]
+	}
+	if !exists || !c.withinGrace(item, now) {
+		return nil, false, false
+	}
+
+	return item.value, true, true
+}
+
+// retire removes an expired item, keeping it for GetStale if StaleGrace is
+// set. The caller must hold the write lock.
+func (c *Basic) retire(item *cacheItem) {
+	c.remove(item)
 
-	c.data[key] = &cacheItem{
-		key:       key,
-		value:     value,
-		expiresAt: time.Now().Add(c.ttl),
+	if c.staleGrace > 0 {
+		if c.stale == nil {
+			c.stale = make(map[string]*cacheItem)
+		}
+		c.stale[item.key] = item
+	}
+}
+
+// withinGrace reports whether an expired item can still be served by GetStale
+// at the given time.
+func (c *Basic) withinGrace(item *cacheItem, now time.Time) bool {
+	return c.staleGrace > 0 && !now.After(c.deadline(item).Add(c.staleGrace))
+}
+
+// pruneStale drops the retired items past their grace period. The caller must
+// hold the write lock.
+func (c *Basic) pruneStale(now time.Time) {
+	for key, item := range c.stale {
+		if !c.withinGrace(item, now) {
+			delete(c.stale, key)
+		}
 	}
 }
 
+func (c *Basic) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	item, exists := c.data[key]
+	if !exists || c.expired(item, c.now()) {
+		return nil, time.Time{}, false
+	}
+
+	return item.value, item.expiresAt, true
+}
+
+func (c *Basic) Set(key string, value any) {
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+
+	c.SetWithTTL(key, value, expiresAt)
+}
+
 func (c *Basic) SetWithTTL(key string, value any, expiresAt time.Time) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	c.data[key] = &cacheItem{
-		key:       key,
-		value:     value,
-		expiresAt: expiresAt,
+	delete(c.stale, key)
+
+	if item, exists := c.data[key]; exists {
+		item.value = value
+		item.expiresAt = expiresAt
+		item.lastAccess.Store(c.now().UnixNano())
+		c.schedule(item)
+		return
+	}
+
+	item := newItem(key, value)
+	item.expiresAt = expiresAt
+	c.seq++
+	item.seq = c.seq
+	if c.rand != nil {
+		item.tiebreak = c.rand()
 	}
+	item.lastAccess.Store(c.now().UnixNano())
+	c.store(item)
+	c.schedule(item)
 }
 
 func (c *Basic) Delete(key string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	delete(c.data, key)
+	delete(c.stale, key)
+
+	item, exists := c.data[key]
+	if !exists {
+		return
+	}
+
+	c.remove(item)
+	releaseItem(item)
 }
 
 func (c *Basic) Has(key string) bool {
@@ -92,21 +551,50 @@ func (c *Basic) Has(key string) bool {
 		return false
 	}
 
-	if time.Now().After(item.expiresAt) {
+	if c.expired(item, c.now()) {
 		return false
 	}
 
 	return true
 }
 
+// Len returns the number of items that have not expired, using the same
+// definition of expiration as Get and Has.
+//
+// It is O(1) as long as no item has expired since the last call. Once one
+// may have, Len removes the expired items first, like Cleanup does, so the
+// count never includes a key that Has reports as missing. The items are kept
+// ordered by deadline, so only the expired ones are visited.
 func (c *Basic) Len() int {
+	c.lock.RLock()
+	if !c.mayHaveExpired(c.now()) {
+		defer c.lock.RUnlock()
+		return len(c.data)
+	}
+	c.lock.RUnlock()
+
+	c.lock.Lock()
+	expired := c.expire(c.now())
+	n := len(c.data)
+	c.lock.Unlock()
+
+	for _, item := range expired {
+		c.notifyExpired(item)
+	}
+
+	return n
+}
+
+// LenExact returns the number of items that have not expired without
+// removing the expired ones. It walks the whole cache, so it is O(n).
+func (c *Basic) LenExact() int {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
 	count := 0
-	now := time.Now()
+	now := c.now()
 	for _, item := range c.data {
-		if item.expiresAt.After(now) {
+		if !c.expired(item, now) {
 			count++
 		}
 	}
@@ -114,16 +602,163 @@ func (c *Basic) Len() int {
 	return count
 }
 
-func (c *Basic) Evict() {
+// Evict removes the item closest to its expiration, so already expired
+// items are always removed first. Among items expiring at the same time, the
+// oldest one is removed, and once no item expires, the oldest item that never
+// expires. The victim is taken from the expiry queue or from the front of the
+// eternal list, so eviction costs O(log n) however many items the cache holds.
+func (c *Basic) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	victim := c.nextVictim()
+	if victim == nil {
+		return "", nil, false
+	}
+
+	c.remove(victim)
+
+	key, value := victim.key, victim.value
+	releaseItem(victim)
+
+	return key, value, true
+}
+
+// nextVictim returns the item Evict removes, or nil if the cache is empty.
+// Reads postpone the idle deadline of an item without rescheduling it, so the
+// first item of the expiry queue is rescheduled until its due time is its
+// actual deadline. The caller must hold the write lock.
+func (c *Basic) nextVictim() *cacheItem {
+	for len(c.expiries) > 0 {
+		item := c.expiries[0]
+		if c.deadline(item).Equal(item.due) {
+			return item
+		}
+		c.schedule(item)
+	}
+
+	if front := c.eternal.Front(); front != nil {
+		return front.Value.(*cacheItem)
+	}
+
+	return nil
+}
+
+// EvictFunc removes the item expiring first among the ones accepted by
+// accept. The candidates are ordered with a heap, so skipping k items costs
+// O(n + k log n) instead of sorting every item.
+func (c *Basic) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	now := time.Now()
+	candidates := byExpiration{c: c, items: make([]*cacheItem, 0, len(c.data))}
+	for _, item := range c.data {
+		candidates.items = append(candidates.items, item)
+	}
+	heap.Init(&candidates)
+
+	for candidates.Len() > 0 {
+		victim := heap.Pop(&candidates).(*cacheItem)
+		if !accept(victim.key, victim.value) {
+			continue
+		}
+
+		c.remove(victim)
+
+		key, value := victim.key, victim.value
+		releaseItem(victim)
+
+		return key, value, true
+	}
+
+	return "", nil, false
+}
+
+// byExpiration is a heap of items ordered by expiresBefore.
+type byExpiration struct {
+	c     *Basic
+	items []*cacheItem
+}
+
+func (h *byExpiration) Len() int           { return len(h.items) }
+func (h *byExpiration) Less(i, j int) bool { return h.c.expiresBefore(h.items[i], h.items[j]) }
+func (h *byExpiration) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *byExpiration) Push(x any) {
+	h.items = append(h.items, x.(*cacheItem))
+}
+
+func (h *byExpiration) Pop() any {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	h.items = old[:n-1]
+	return item
+}
+
+func (c *Basic) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	items := make([]*cacheItem, 0, len(c.data))
+	now := c.now()
+	for _, item := range c.data {
+		if !c.expired(item, now) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return c.expiresBefore(items[i], items[j])
+	})
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.key)
+	}
+
+	return keys
+}
+
+func (c *Basic) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	now := c.now()
 	for key, item := range c.data {
-		if item.expiresAt.Before(now) {
-			delete(c.data, key)
+		if c.expired(item, now) {
+			continue
 		}
+		if !fn(key, item.value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index, and the cleanup buckets, to hold n items
+// without rehashing.
+func (c *Basic) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	c.resize(n)
+}
+
+func (c *Basic) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*cacheItem)
+	for i := range c.buckets {
+		c.buckets[i] = make(map[string]*cacheItem)
 	}
+	c.stale = nil
+	c.expiries = nil
+	c.eternal.Init()
+	c.capacity = 0
 }
 
 func (c *Basic) IsExpirable() bool {
@@ -131,36 +766,115 @@ func (c *Basic) IsExpirable() bool {
 }
 
 func (c *Basic) IsExpired(key string) bool {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 
 	item, exists := c.data[key]
 	if !exists {
 		return true
 	}
 
-	return time.Now().After(item.expiresAt)
+	return c.expired(item, c.now())
 }
 
-func (c *Basic) startCleanup() {
-	ticker := time.NewTicker(c.cleanupInterval)
-	defer ticker.Stop()
+func (c *Basic) Touch(key string, expiresAt time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	for range ticker.C {
-		c.cleanupExpiredItems()
+	item, exists := c.data[key]
+	if !exists || c.expired(item, c.now()) {
+		return false
 	}
+
+	item.expiresAt = expiresAt
+	c.schedule(item)
+	return true
 }
 
-func (c *Basic) cleanupExpiredItems() {
+func (c *Basic) startCleanup() {
+	timer := time.NewTimer(c.CleanupInterval())
+	defer timer.Stop()
+
 	for {
-		time.Sleep(time.Second)
-		c.lock.Lock()
-		now := time.Now()
-		for key, item := range c.data {
-			if item.expiresAt.Before(now) {
-				delete(c.data, key)
+		select {
+		case <-timer.C:
+			c.Cleanup()
+			timer.Reset(c.CleanupInterval())
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Close stops the periodic cleanup of expired items. Expired items are still
+// hidden from reads after Close, but no longer removed in the background.
+func (c *Basic) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// CleanupInterval returns the current interval between two cleanups. It only
+// changes if Options.MinCleanupInterval or MaxCleanupInterval is set.
+func (c *Basic) CleanupInterval() time.Duration {
+	return time.Duration(c.cleanupInterval.Load())
+}
+
+// setCleanupInterval sets the interval between two cleanups, within bounds.
+func (c *Basic) setCleanupInterval(d time.Duration) {
+	d = min(max(d, c.minCleanupInterval), c.maxCleanupInterval)
+	c.cleanupInterval.Store(int64(d))
+}
+
+// adaptCleanupInterval shortens the interval after a cleanup that removed
+// many of the items it swept, and lengthens it after one that removed none.
+func (c *Basic) adaptCleanupInterval(removed, swept int) {
+	switch interval := c.CleanupInterval(); {
+	case removed == 0:
+		c.setCleanupInterval(2 * interval)
+	case removed*burstRatio >= swept:
+		c.setCleanupInterval(interval / 2)
+	}
+}
+
+// Cleanup removes expired items and returns how many were removed. It runs on
+// every cleanup tick and sweeps a single bucket of keys if
+// Options.CleanupBatchFraction is set, or the whole cache otherwise. The
+// number of removed items adapts the interval until the next tick.
+func (c *Basic) Cleanup() int {
+	var expired []*cacheItem
+	var swept int
+
+	c.lock.Lock()
+	now := c.now()
+	if c.buckets != nil {
+		swept = len(c.buckets[c.cursor])
+		for _, item := range c.buckets[c.cursor] {
+			if c.expired(item, now) {
+				c.retire(item)
+				expired = append(expired, item)
 			}
 		}
-		c.lock.Unlock()
+		c.cursor = (c.cursor + 1) % len(c.buckets)
+	} else {
+		swept = len(c.data)
+		expired = c.expire(now)
+	}
+	c.pruneStale(now)
+	c.shrink()
+	c.lock.Unlock()
+
+	c.adaptCleanupInterval(len(expired), swept)
+
+	for _, item := range expired {
+		c.notifyExpired(item)
+	}
+
+	return len(expired)
+}
+
+func (c *Basic) notifyExpired(item *cacheItem) {
+	if c.onExpire != nil {
+		c.onExpire(item.key, item.value)
 	}
 }
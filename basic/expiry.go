@@ -0,0 +1,112 @@
+package basic
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryQueue is a min-heap of the items that expire, ordered by due: the
+// deadline they had when last scheduled. Reads postpone the idle deadline of
+// an item without rescheduling it under the read lock, so due is a lower
+// bound of the actual deadline, and items found alive at their due time are
+// rescheduled instead of removed.
+type expiryQueue []*cacheItem
+
+func (q expiryQueue) Len() int { return len(q) }
+
+func (q expiryQueue) Less(i, j int) bool {
+	if q[i].due.Equal(q[j].due) {
+		return q[i].tiedBefore(q[j])
+	}
+	return q[i].due.Before(q[j].due)
+}
+
+func (q expiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expiryQueue) Push(x any) {
+	item := x.(*cacheItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *expiryQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	item.index = -1
+	*q = old[:len(old)-1]
+	return item
+}
+
+// schedule places an item in the expiry queue at its current deadline, or at
+// the back of the eternal list if it never expires. The caller must hold the
+// write lock.
+func (c *Basic) schedule(item *cacheItem) {
+	deadline := c.deadline(item)
+	if deadline.IsZero() {
+		if item.index >= 0 {
+			heap.Remove(&c.expiries, item.index)
+		}
+		if item.elem == nil {
+			c.seq++
+			item.seq = c.seq
+			item.elem = c.eternal.PushBack(item)
+		}
+		return
+	}
+
+	if item.elem != nil {
+		c.eternal.Remove(item.elem)
+		item.elem = nil
+	}
+
+	item.due = deadline
+	if item.index >= 0 {
+		heap.Fix(&c.expiries, item.index)
+		return
+	}
+	heap.Push(&c.expiries, item)
+}
+
+// unschedule takes an item out of the expiry queue or the eternal list. The
+// caller must hold the write lock.
+func (c *Basic) unschedule(item *cacheItem) {
+	if item.index >= 0 {
+		heap.Remove(&c.expiries, item.index)
+	}
+	if item.elem != nil {
+		c.eternal.Remove(item.elem)
+		item.elem = nil
+	}
+}
+
+// mayHaveExpired reports whether an item may have expired at the given time.
+// The caller must hold the lock.
+func (c *Basic) mayHaveExpired(now time.Time) bool {
+	return len(c.expiries) > 0 && now.After(c.expiries[0].due)
+}
+
+// expire removes the items expired at the given time and returns them. It
+// only visits the items due by then, so it costs O(log n) per item removed
+// or rescheduled, however many items the cache holds. The caller must hold
+// the write lock.
+func (c *Basic) expire(now time.Time) []*cacheItem {
+	var expired []*cacheItem
+
+	for c.mayHaveExpired(now) {
+		item := c.expiries[0]
+		if !c.expired(item, now) {
+			c.schedule(item)
+			continue
+		}
+
+		c.retire(item)
+		expired = append(expired, item)
+	}
+
+	return expired
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// LastAccess returns the time a key was last read with Get, to help find hot
+// keys while debugging. The zero time means the key was never read.
+//
+// Access times are only recorded by the LRU and LFU policies (without
+// LFUScore); LastAccess returns false for the other policies, and for missing
+// keys. Reading the access time does not count as an access. Access times come
+// from Config.Clock.
+func (c *Cache) LastAccess(key string) (time.Time, bool) {
+	key = c.transformKey(key)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	tracker, ok := c.engine.(engine.AccessTracker)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return tracker.LastAccess(key)
+}
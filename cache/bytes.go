@@ -0,0 +1,26 @@
+package cache
+
+// SetBytes stores a byte slice, such as a serialized message or an HTTP body,
+// like Set. The value is sized by its length for MaxValueBytes and
+// EstimateSize, without calling the configured Sizer.
+//
+// The slice is stored as is, not copied, so it must not be modified after
+// the call.
+func (c *Cache) SetBytes(key string, b []byte) {
+	c.store(key, b, len(b))
+}
+
+// GetBytes retrieves a byte slice stored with SetBytes, or with Set. It
+// returns false if the key is missing or expired, or if its value is not a
+// byte slice.
+//
+// The returned slice is shared with the cache and must not be modified.
+func (c *Cache) GetBytes(key string) ([]byte, bool) {
+	value, found := c.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	b, ok := value.([]byte)
+	return b, ok
+}
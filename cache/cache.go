@@ -1,14 +1,17 @@
 package cache
 
 import (
-	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unique"
 
 	"github.com/hugocarreira/easycache/basic"
+	"github.com/hugocarreira/easycache/clock"
 	"github.com/hugocarreira/easycache/fifo"
 	"github.com/hugocarreira/easycache/lfu"
 	"github.com/hugocarreira/easycache/lru"
+	"github.com/hugocarreira/easycache/lruk"
 
 	"github.com/hugocarreira/easycache/engine"
 )
@@ -18,10 +21,13 @@ import (
 // The eviction policy determines how items are removed when the cache reaches
 // its maximum size. The available policies are:
 //
-//   - Basic: No automatic eviction, items are removed only when they expire (TTL-based).
+//   - Basic: Items are removed when they expire (TTL-based), or closest to expiring first once MaxSize is reached.
 //   - FIFO: First-In, First-Out eviction; the oldest item is removed first.
 //   - LRU: Least Recently Used eviction; the least accessed item is removed first.
 //   - LFU: Least Frequently Used eviction; the item with the fewest accesses is removed first.
+//   - LRUK: LRU-K eviction; the item whose K-th most recent access is the oldest is removed first.
+//   - Clock: Second-chance FIFO; the oldest item is removed first unless it was accessed since the last sweep.
+//   - Custom: The engine created by Config.CustomEngine; it can't be built by the cache itself.
 type EvictionPolicy int
 
 const (
@@ -29,8 +35,33 @@ const (
 	FIFO
 	LRU
 	LFU
+	LRUK
+	Clock
+	Custom
 )
 
+// String returns the lowercase name of the eviction policy, such as "lru".
+func (p EvictionPolicy) String() string {
+	switch p {
+	case Basic:
+		return "basic"
+	case FIFO:
+		return "fifo"
+	case LRU:
+		return "lru"
+	case LFU:
+		return "lfu"
+	case LRUK:
+		return "lruk"
+	case Clock:
+		return "clock"
+	case Custom:
+		return "custom"
+	default:
+		return "unknown"
+	}
+}
+
 // Cache is the main structure that manages an in-memory key-value store
 // with different eviction policies and optional TTL-based expiration.
 //
@@ -47,17 +78,109 @@ type Cache struct {
 	// It implements the CacheInterface to allow dynamic eviction policies.
 	engine engine.Engine
 
+	// policy is the current eviction policy. It starts from
+	// Config.EvictionPolicy and can be changed with SwitchPolicy.
+	policy EvictionPolicy
+
 	// Config holds the configuration settings, such as eviction policy,
 	// max size, and TTL (if applicable).
 	config *Config
 
 	// metrics tracks cache statistics, including hits and misses.
 	metrics *Metrics
+
+	// metricsEnabled reports whether metrics are collected. It starts from
+	// Config.Metrics and can be toggled at runtime with EnableMetrics.
+	metricsEnabled atomic.Bool
+
+	// events receives expiration and eviction notifications once Events is called.
+	events        chan Event
+	eventsOnce    sync.Once
+	eventsEnabled atomic.Bool
+	droppedEvents atomic.Uint64
+
+	// ghosts remembers the recently evicted keys to count the eviction regret.
+	ghosts *ghostList
+
+	// loads holds the GetOrSet calls loading a key, by transformed key.
+	loadLock sync.Mutex
+	loads    map[string]*loadCall
+
+	// emptyCond is signaled when items are removed while WaitEmpty calls,
+	// counted by emptyWaiters, are waiting. removals counts the signals, so
+	// that a waiter can tell a removal happened. They are guarded by emptyLock.
+	emptyLock    sync.Mutex
+	emptyCond    *sync.Cond
+	emptyWaiters atomic.Int32
+	removals     uint64
+
+	// refreshers holds a channel stopping the refresher of every key
+	// registered with RegisterRefresher, by transformed key.
+	refreshLock sync.Mutex
+	refreshers  map[string]chan struct{}
+
+	// pinned holds the keys exempt from eviction, set with Pin. It is
+	// guarded by lock.
+	pinned map[string]struct{}
+
+	// preloading is set while Preload runs, so that its evictions are not
+	// reported. It is guarded by lock.
+	preloading bool
+
+	// memoryPressure is set while the memory check evicts down to the low
+	// watermark, across checks if needed. It is only used by the memory check
+	// goroutine.
+	memoryPressure bool
+
+	// rejectedSets counts writes dropped by MaxKeyBytes and MaxValueBytes.
+	rejectedSets atomic.Uint64
+
+	// sizes holds the size of every entry when Config.EstimateSize is set,
+	// and estimatedBytes their sum. They are guarded by sizeLock since
+	// expired entries are reported by the engines outside the cache lock.
+	sizeLock       sync.Mutex
+	sizes          map[string]int
+	estimatedBytes int
+
+	// versions holds the version of every entry once a versioned method has
+	// been called, and lastVersion the last one given. Like the sizes, they
+	// are guarded by their own lock.
+	versioned   atomic.Bool
+	versionLock sync.Mutex
+	versions    map[string]uint64
+	lastVersion uint64
+
+	// interned holds the intern pool handle of every stored key when
+	// Config.InternKeys is set, so the pool keeps the canonical copy for as
+	// long as the entry is cached. Like the sizes, it is guarded by its own
+	// lock.
+	internLock sync.Mutex
+	interned   map[string]unique.Handle[string]
+
+	// randLock serializes the use of Config.Rand, which is not safe for
+	// concurrent use.
+	randLock sync.Mutex
+
+	// shadow, if set, receives a copy of Get, Set and Delete calls (see AttachShadow).
+	shadow atomic.Pointer[Cache]
+
+	// done stops the background goroutines once the cache is closed.
+	done      chan struct{}
+	closeOnce sync.Once
+	closed    atomic.Bool
 }
 
+// New creates a cache with the given configuration, or the default one if cfg is nil.
+//
+// The configuration is copied, so later changes to cfg do not affect the cache.
+// New does not validate the configuration; use NewWithError to reject invalid settings.
+// A policy without an engine to build, such as Custom without a CustomEngine,
+// falls back to Basic, which Policy and Stats then report.
 func New(cfg *Config) *Cache {
 	if cfg == nil {
 		cfg = defaultConfig()
+	} else {
+		cfg = cfg.Clone()
 	}
 
 	if cfg.CleanupInterval <= 0 {
@@ -67,53 +190,192 @@ func New(cfg *Config) *Cache {
 	c := &Cache{
 		config:  cfg,
 		metrics: NewMetrics(),
+		done:    make(chan struct{}),
+	}
+	c.metrics.clock = c.now
+	c.ghosts = newGhostList(c.ghostSize())
+	c.emptyCond = sync.NewCond(&c.emptyLock)
+	c.metricsEnabled.Store(cfg.Metrics)
+
+	c.policy = cfg.EvictionPolicy
+	if cfg.CustomEngine != nil {
+		c.policy = Custom
+		c.engine = cfg.CustomEngine(cfg.MaxSize)
+		c.reserveInitialCapacity(c.engine)
+	} else {
+		c.engine = c.newEngine(cfg.EvictionPolicy)
+
+		// newEngine builds Basic for a policy without a built-in engine, such
+		// as Custom without a CustomEngine, so report the engine actually in
+		// use. NewWithError rejects such a config instead.
+		switch c.policy {
+		case FIFO, LRU, LFU, LRUK, Clock:
+		default:
+			c.policy = Basic
+		}
 	}
 
-	switch cfg.EvictionPolicy {
+	// The background goroutines are only started when configured, so that
+	// short-lived caches don't each spawn goroutines with nothing to do.
+	if cfg.memoryHighWatermark() > 0 && cfg.MemoryCheckInterval > 0 {
+		go c.startCheckMemoryUsage()
+	}
+	if cfg.MetricsLogger != nil && cfg.MetricsLogInterval > 0 {
+		go c.startMetricsLogger()
+	}
+
+	return c
+}
+
+// newEngine creates the engine of the given policy, sized and tuned by the
+// cache configuration.
+func (c *Cache) newEngine(policy EvictionPolicy) engine.Engine {
+	var e engine.Engine
+
+	switch policy {
 	case LRU:
-		c.engine = lru.New(cfg.MaxSize)
+		e = lru.New(c.config.MaxSize)
 	case FIFO:
-		c.engine = fifo.New(cfg.MaxSize)
+		e = fifo.NewWithOptions(fifo.Options{
+			MaxSize:      c.config.MaxSize,
+			OnExpire:     c.expired,
+			Clock:        c.config.Clock,
+			DeleteOnRead: c.config.LazyExpiryDelete,
+		})
 	case LFU:
-		c.engine = lfu.New(cfg.MaxSize)
+		if c.config.LFUScore != nil {
+			e = lfu.NewWithScore(c.config.MaxSize, c.config.LFUScore)
+		} else if c.config.LFUApproxCounters {
+			e = lfu.NewApprox(c.config.MaxSize)
+		} else {
+			e = lfu.New(c.config.MaxSize)
+		}
+		if limiter, ok := e.(interface{ SetMaxFrequency(int) }); ok {
+			limiter.SetMaxFrequency(c.config.LFUMaxFrequency)
+		}
+		if debouncer, ok := e.(interface {
+			SetCountWindow(time.Duration, func() time.Time)
+		}); ok && c.config.LFUCountWindow > 0 {
+			debouncer.SetCountWindow(c.config.LFUCountWindow, c.now)
+		}
+		if weighted, ok := e.(*lfu.Weighted); ok {
+			c.watchCorruption(weighted)
+		}
+	case LRUK:
+		e = lruk.New(c.config.MaxSize, c.config.LRUK)
+	case Clock:
+		e = clock.New(c.config.MaxSize)
 	default:
-		c.engine = basic.New(cfg.MaxSize, cfg.TTL, cfg.CleanupInterval)
+		e = basic.NewWithOptions(basic.Options{
+			MaxSize:              c.config.MaxSize,
+			TTL:                  c.config.TTL,
+			CleanupInterval:      c.config.CleanupInterval,
+			MinCleanupInterval:   c.config.MinCleanupInterval,
+			MaxCleanupInterval:   c.config.MaxCleanupInterval,
+			CleanupBatchFraction: c.config.CleanupBatchFraction,
+			DeleteOnRead:         c.config.LazyExpiryDelete,
+			MaxIdle:              c.config.MaxIdle,
+			AutoShrink:           c.config.AutoShrink,
+			StaleGrace:           c.config.StaleGrace,
+			OnExpire:             c.expired,
+			Clock:                c.config.Clock,
+			Rand:                 c.randTiebreak(),
+		})
 	}
 
-	go c.startCheckMemoryUsage()
+	if clocked, ok := e.(interface{ SetClock(func() time.Time) }); ok && c.config.Clock != nil {
+		clocked.SetClock(c.config.Clock)
+	}
 
-	return c
+	c.reserveInitialCapacity(e)
+
+	return e
 }
 
-// startCheckMemoryUsage periodically monitors the cache's memory usage.
-//
-// If memory limits are set in CacheConfig, this function runs at the configured
-// interval (`MemoryCheckInterval`). When memory usage exceeds `MemoryLimits`,
-// the cache triggers cleanup to free up space
-func (c *Cache) startCheckMemoryUsage() {
-	if c.config.MemoryLimits == 0 {
-		return
+// reserveInitialCapacity preallocates the engine for Config.InitialCapacity
+// items, if it supports it.
+func (c *Cache) reserveInitialCapacity(e engine.Engine) {
+	if reserver, ok := e.(engine.Reserver); ok && c.config.InitialCapacity > 0 {
+		reserver.Reserve(c.config.InitialCapacity)
 	}
+}
 
-	if c.config.MemoryCheckInterval <= 0 {
+// watchCorruption reports the heap rebuilds of a weighted LFU engine to
+// Config.LFUOnCorruption.
+func (c *Cache) watchCorruption(e *lfu.Weighted) {
+	if c.config.LFUOnCorruption == nil {
 		return
 	}
 
-	ticker := time.NewTicker(c.config.MemoryCheckInterval)
-	defer ticker.Stop()
+	e.SetOnCorruption(func(recovered any) {
+		c.callback(func() { c.config.LFUOnCorruption(recovered) })
+	})
+}
 
-	maxMem := uint64(c.config.MemoryLimits) * 1024 * 1024
+// expired is called by the engines for every expired item they remove.
+func (c *Cache) expired(key string, value any) {
+	c.forget(key)
+	c.emit(key, ReasonExpired)
+	c.removed(value)
+}
 
-	for range ticker.C {
-		var mem runtime.MemStats
-		runtime.ReadMemStats(&mem)
-		memAlloc := mem.Alloc / 1024 / 1024
-		if memAlloc > maxMem {
-			c.lock.Lock()
-			c.engine.Evict()
-			c.lock.Unlock()
+// NewWithError creates a cache like New, but returns an error if the
+// configuration is invalid (see Config.Validate).
+func NewWithError(cfg *Config) (*Cache, error) {
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
 		}
 	}
+
+	return New(cfg), nil
+}
+
+// now returns the current time according to the configured clock.
+func (c *Cache) now() time.Time {
+	if c.config.Clock != nil {
+		return c.config.Clock()
+	}
+
+	return time.Now()
+}
+
+// clampTTL raises ttl to Config.MinTTL.
+func (c *Cache) clampTTL(ttl time.Duration) time.Duration {
+	return max(ttl, c.config.MinTTL)
+}
+
+// ttlDeadline returns the deadline of an entry given a lifetime of ttl from
+// now, the way every method taking a TTL reads it: a ttl of 0 uses the
+// configured TTL, the entry never expires if the ttl is still not positive,
+// and it is raised to Config.MinTTL otherwise.
+func (c *Cache) ttlDeadline(now time.Time, ttl time.Duration) time.Time {
+	if ttl == 0 {
+		ttl = c.config.TTL
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(c.clampTTL(ttl))
+}
+
+// clampDeadline raises a non-zero deadline to Config.MinTTL from now.
+func (c *Cache) clampDeadline(deadline time.Time) time.Time {
+	if c.config.MinTTL <= 0 || deadline.IsZero() {
+		return deadline
+	}
+
+	if floor := c.now().Add(c.config.MinTTL); deadline.Before(floor) {
+		return floor
+	}
+
+	return deadline
+}
+
+// recordLatency records the time elapsed since start.
+func (c *Cache) recordLatency(record func(time.Duration), start time.Time) {
+	record(c.now().Sub(start))
 }
 
 // Get retrieves a value from the cache by its key.
@@ -121,74 +383,596 @@ func (c *Cache) startCheckMemoryUsage() {
 // If the key exists and has not expired, the function returns the value and true.
 // If the key does not exist or has expired (in case of TTL-based eviction),
 // the function returns nil and false. Additionally, cache hit/miss metrics
-// are updated accordingly.
+// are updated and the OnHit/OnMiss callbacks are called (outside the lock)
+// accordingly.
 func (c *Cache) Get(key string) (any, bool) {
-	elem, exists := c.engine.Get(key)
+	key = c.transformKey(key)
+
+	if c.config.LatencyMetrics {
+		defer c.recordLatency(c.metrics.RecordGetLatency, c.now())
+	}
+
+	c.lock.RLock()
+	elem, exists, expired := c.lookup(key)
+	if exists && c.expiresEarly(key) {
+		elem, exists = nil, false
+	}
+	c.lock.RUnlock()
+
+	if expired {
+		c.deleteExpired(key)
+	}
+
+	if shadow := c.shadow.Load(); shadow != nil {
+		shadow.Get(key)
+	}
 
 	if !exists {
-		if c.config.Metrics {
-			c.metrics.IncrementMisses()
+		c.recordMiss(key, expired)
+		if c.config.OnMiss != nil {
+			c.callback(func() { c.config.OnMiss(key) })
 		}
 		return nil, false
 	}
 
-	if c.engine.IsExpirable() {
-		if c.engine.IsExpired(key) {
-			c.lock.RUnlock()
-			c.lock.Lock()
-			go c.engine.Delete(key)
-			c.lock.Unlock()
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+	if c.config.OnHit != nil {
+		c.callback(func() { c.config.OnHit(key) })
+	}
+
+	return elem, true
+}
+
+// lookup retrieves a value from the engine, copied if CopyOnGet is set.
+// Expired values are reported as missing, with expired set; a caller holding
+// only the read lock then calls deleteExpired once it has released it, and a
+// caller holding the write lock calls deleteIfExpired. The caller must hold
+// the lock.
+func (c *Cache) lookup(key string) (value any, found, expired bool) {
+	elem, found, expired := c.engineGet(key)
+	if !found || expired {
+		return nil, false, expired
+	}
+
+	return c.copyValue(c.unwrap(elem)), true, false
+}
+
+// engineGet retrieves a value from the engine. expired is set if the key is
+// present but expired, and found then reports whether the engine still holds
+// it. The caller must hold the lock.
+func (c *Cache) engineGet(key string) (elem any, found, expired bool) {
+	if reporter, ok := c.engine.(engine.ExpiryReporter); ok {
+		elem, found, expired = reporter.GetOrExpired(key)
+	} else {
+		elem, found = c.engine.Get(key)
+	}
+
+	if found && c.engine.IsExpirable() && c.engine.IsExpired(key) {
+		expired = true
+	}
 
-			if c.config.Metrics {
-				c.metrics.IncrementMisses()
-			}
-			return nil, false
+	return elem, found, expired
+}
+
+// deleteExpired deletes key if LazyExpiryDelete is set and the key is still
+// expired once the write lock is held, so that concurrent readers of the same
+// expired key delete it only once.
+func (c *Cache) deleteExpired(key string) {
+	if !c.config.LazyExpiryDelete {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deleteIfExpired(key)
+}
+
+// deleteIfExpired deletes key if LazyExpiryDelete is set and the engine still
+// holds it expired. Engines that delete expired keys on read themselves
+// report them through Config.OnExpire instead. The caller must hold the write
+// lock.
+func (c *Cache) deleteIfExpired(key string) {
+	if !c.config.LazyExpiryDelete {
+		return
+	}
+
+	elem, found, expired := c.engineGet(key)
+	if !found || !expired {
+		return
+	}
+
+	c.engine.Delete(key)
+	c.forget(key)
+	c.emit(key, ReasonExpired)
+	c.removed(elem)
+}
+
+// GetStale retrieves a value like Get, but an item that expired less than
+// Config.StaleGrace ago is returned with stale set instead of reported as a
+// miss, so the caller can serve it while refreshing it in the background.
+//
+// Fresh values count as hits, stale ones as expirations and missing ones as
+// misses. Policies without stale support behave like Get and never report a
+// stale value.
+func (c *Cache) GetStale(key string) (value any, stale bool, ok bool) {
+	key = c.transformKey(key)
+
+	var expired bool
+
+	c.lock.RLock()
+	if reader, isReader := c.engine.(engine.StaleReader); isReader {
+		value, stale, ok = reader.GetStale(key)
+		if ok {
+			value = c.copyValue(c.unwrap(value))
 		}
+		expired = stale
+	} else {
+		value, ok, expired = c.lookup(key)
 	}
+	c.lock.RUnlock()
+
+	if expired && !stale {
+		c.deleteExpired(key)
+	}
+
+	if ok && !stale {
+		if c.metricsEnabled.Load() {
+			c.metrics.IncrementHits()
+		}
+	} else {
+		c.recordMiss(key, expired)
+	}
+
+	return value, stale, ok
+}
+
+// recordMiss counts a read of key that found no value, as an expiration if
+// the key was present but expired and as a miss otherwise. A read of a key
+// that was recently evicted also counts as eviction regret.
+func (c *Cache) recordMiss(key string, expired bool) {
+	if !c.metricsEnabled.Load() {
+		return
+	}
+
+	if c.ghosts.remove(key) {
+		c.metrics.IncrementEvictionRegret()
+	}
+
+	if expired {
+		c.metrics.IncrementExpirations()
+	} else {
+		c.metrics.IncrementMisses()
+	}
+}
+
+// GetAndDelete retrieves a value from the cache and removes it in a single
+// atomic operation.
+//
+// It returns the value and true if the key existed and had not expired. Among
+// concurrent callers for the same key, only one receives the value. Cache
+// hit/miss metrics are updated like in Get.
+func (c *Cache) GetAndDelete(key string) (any, bool) {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists, expired := c.lookup(key)
+	if !exists {
+		if expired {
+			c.deleteIfExpired(key)
+		}
+		c.recordMiss(key, expired)
+		return nil, false
+	}
+
+	c.engine.Delete(key)
+	c.forget(key)
 
-	if c.config.Metrics {
+	if c.metricsEnabled.Load() {
 		c.metrics.IncrementHits()
 	}
 
 	return elem, true
 }
 
+// LoadOrStore returns the existing value for the key if present. Otherwise,
+// it stores and returns the given value.
+//
+// The loaded result is true if the value was loaded, false if stored. It follows
+// the semantics of sync.Map.LoadOrStore, and both the lookup and the insertion
+// happen atomically under the cache lock. Expired keys are treated as absent.
+func (c *Cache) LoadOrStore(key string, value any) (any, bool) {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	actual, loaded, expired := c.lookup(key)
+	if loaded {
+		if c.metricsEnabled.Load() {
+			c.metrics.IncrementHits()
+		}
+		return actual, true
+	}
+	if expired {
+		c.deleteIfExpired(key)
+	}
+
+	if c.accept(key, value) {
+		c.set(key, value)
+	}
+
+	c.recordMiss(key, expired)
+
+	return value, false
+}
+
 // Set stores a key-value pair in the cache.
 //
 // If the key already exists, its value is updated. If the cache has a size limit
 // (`MaxSize`) and is full, the eviction policy (FIFO, LRU, LFU) is applied to remove an item
 // before inserting the new one. If TTL is enabled, the item will expire after the configured duration.
-func (c *Cache) Set(key string, value string) {
-	if c.engine.IsExpirable() {
-		expiration := time.Now().Add(c.config.TTL)
-		c.engine.SetWithTTL(key, value, expiration)
+//
+// Any value can be stored, including nil: a stored nil is returned by Get as
+// (nil, true), which tells it apart from a missing key.
+//
+// With Config.SkipIfEqual, setting a key to the value it already holds does
+// nothing: the eviction order, the expiration and the metrics are unchanged.
+func (c *Cache) Set(key string, value any) {
+	c.store(key, value, unknownSize)
+}
 
-		if c.config.Metrics {
-			c.metrics.IncrementHits()
-		}
+// store implements Set, SetE and SetBytes, for a value of the given size or of
+// unknownSize. It returns false if the entry was rejected by MaxKeyBytes or
+// MaxValueBytes.
+func (c *Cache) store(key string, value any, size int) bool {
+	key = c.transformKey(key)
+
+	if c.config.LatencyMetrics {
+		defer c.recordLatency(c.metrics.RecordSetLatency, c.now())
+	}
+
+	if !c.acceptSized(key, value, size) {
+		return false
+	}
+
+	if shadow := c.shadow.Load(); shadow != nil {
+		shadow.Set(key, value)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.config.SkipIfEqual && c.unchanged(key, value) {
+		return true
+	}
+
+	c.setSized(key, value, size)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return true
+}
+
+// SetIfAbsent stores a key-value pair only if the key is not in the cache.
+//
+// Expired keys are treated as absent. The check and the insertion happen
+// atomically under the cache lock, so among concurrent callers for the same
+// key exactly one succeeds. It returns true if the value was stored, and false
+// if the key is present or the entry exceeds MaxKeyBytes or MaxValueBytes.
+func (c *Cache) SetIfAbsent(key string, value any) bool {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.engine.Has(key) || !c.accept(key, value) {
+		return false
+	}
+
+	c.set(key, value)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return true
+}
+
+// SetNX stores a key-value pair expiring after ttl, only if the key is not in
+// the cache, like SetIfAbsent. With Redis' SET NX EX semantics, it can act as
+// a lock within a single process: the caller that stores the key holds the
+// lock until it deletes the key, or until the ttl releases it.
+//
+// A ttl of 0 uses the configured TTL. Policies without expiration support
+// store the value and ignore the ttl, so the lock is only released by Delete.
+func (c *Cache) SetNX(key string, value any, ttl time.Duration) bool {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.engine.Has(key) || !c.accept(key, value) {
+		return false
+	}
+
+	c.setWithDeadline(key, value, c.ttlDeadline(c.now(), ttl), unknownSize)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return true
+}
 
+// SetWeighted stores a key-value pair in the cache with a weight (cost).
+//
+// With the LFU policy, items are ordered for eviction by a score combining
+// their access frequency and weight, `Config.LFUScore` or frequency * weight
+// by default, so expensive-to-recompute items are retained longer. The first
+// weight other than 1 switches the default O(1) LFU engine to the heap-based
+// one, keeping the stored items and frequencies. With LFUApproxCounters or
+// other policies, the weight is ignored and it behaves like Set.
+func (c *Cache) SetWeighted(key string, value any, weight float64) {
+	key = c.transformKey(key)
+
+	if !c.accept(key, value) {
 		return
 	}
 
-	if c.engine.Has(key) {
-		c.engine.Set(key, value)
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-		if c.config.Metrics {
-			c.metrics.IncrementHits()
+	if exact, ok := c.engine.(*lfu.LFU); ok && weight != 1 {
+		weighted := exact.ToWeighted(nil)
+		c.watchCorruption(weighted)
+		c.engine = weighted
+	}
+
+	if weighted, ok := c.engine.(engine.Weighted); ok && !c.engine.IsExpirable() {
+		key = c.internKey(key)
+		c.makeRoom(key)
+		c.ghosts.remove(key)
+		value = c.compress(value)
+		c.trackSize(key, value, unknownSize)
+		c.bumpVersion(key)
+		weighted.SetWeighted(key, value, weight)
+	} else {
+		c.set(key, value)
+	}
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+}
+
+// Touch resets the expiration of an existing key to now + ttl, without
+// changing its value.
+//
+// It can both extend and shorten the lifetime of the key, down to
+// Config.MinTTL. Like in SetNX, a ttl of 0 uses the configured TTL, and the
+// key never expires if that is 0 too. Returns true if the key exists and has
+// not expired. For eviction policies without TTL-based expiration (LRU, LFU,
+// LRUK, Clock), it does nothing and returns false.
+func (c *Cache) Touch(key string, ttl time.Duration) bool {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.engine.IsExpirable() {
+		return false
+	}
+
+	return c.engine.Touch(key, c.ttlDeadline(c.now(), ttl))
+}
+
+// Preload seeds the cache with all the given items under a single lock.
+//
+// It is meant for warming up the cache on startup, so it bypasses the metrics
+// accounting. Items are inserted following the same rules as Set: if the number
+// of items exceeds MaxSize, the eviction policy (FIFO, LRU, LFU) removes entries
+// as new ones are inserted. Since map iteration order is random, which of the
+// preloaded items survive in that case is unspecified. These evictions are not
+// counted in the metrics, emitted as events nor reported to the callbacks of
+// SetWithExpireCallback.
+func (c *Cache) Preload(items map[string]any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.preloading = true
+	defer func() { c.preloading = false }()
+
+	for key, value := range items {
+		key = c.transformKey(key)
+		if c.accept(key, value) {
+			c.set(key, value)
 		}
+	}
+}
+
+// set stores a key-value pair, evicting an item first if the cache is full.
+// The caller must hold the write lock.
+func (c *Cache) set(key string, value any) {
+	c.setSized(key, value, unknownSize)
+}
 
+// setSized is set for a value of the given size, or of unknownSize.
+// The caller must hold the write lock.
+func (c *Cache) setSized(key string, value any, size int) {
+	var expiration time.Time
+	if c.engine.IsExpirable() && c.config.TTL > 0 {
+		expiration = c.now().Add(c.clampTTL(c.config.TTL))
+	}
+
+	if c.config.PreserveTTLOnUpdate && c.engine.IsExpirable() {
+		if _, expiresAt, found := c.engine.Peek(key); found {
+			expiration = expiresAt
+		}
+	}
+
+	c.setWithDeadline(key, value, expiration, size)
+}
+
+// SetWithDeadline stores a value that expires at the given absolute time, such
+// as the expiration of a token, instead of after the configured TTL.
+//
+// A zero deadline means the value never expires, and one closer than
+// Config.MinTTL is pushed back to it. Policies without expiration support
+// store the value and ignore the deadline.
+func (c *Cache) SetWithDeadline(key string, value any, deadline time.Time) {
+	key = c.transformKey(key)
+
+	if c.config.LatencyMetrics {
+		defer c.recordLatency(c.metrics.RecordSetLatency, c.now())
+	}
+
+	if !c.accept(key, value) {
 		return
 	}
 
-	if c.config.MaxSize > 0 && c.Len() >= c.config.MaxSize {
-		c.engine.Evict()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setWithDeadline(key, value, c.clampDeadline(deadline), unknownSize)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
 	}
+}
 
-	c.engine.Set(key, value)
+// Increment atomically adds delta to the int stored under key and returns the
+// new value, e.g. to count events per key from many goroutines.
+//
+// A missing or expired key, or one holding a value that is not an int, is
+// stored as delta, expiring at deadline like with SetWithDeadline. An existing
+// counter keeps its expiration. The read and the write happen under the cache
+// lock, so concurrent increments are never lost. It returns delta without
+// storing it if the key exceeds MaxKeyBytes.
+func (c *Cache) Increment(key string, delta int, deadline time.Time) int {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.accept(key, delta) {
+		return delta
+	}
 
-	if c.config.Metrics {
+	value, deadline := delta, c.clampDeadline(deadline)
+	if stored, expiresAt, found := c.engine.Peek(key); found {
+		if n, ok := c.unwrap(stored).(int); ok {
+			value, deadline = n+delta, expiresAt
+		}
+	}
+
+	c.setWithDeadline(key, value, deadline, unknownSize)
+
+	if c.metricsEnabled.Load() {
 		c.metrics.IncrementHits()
 	}
+
+	return value
+}
+
+// ItemWithTTL is a value stored by SetManyWithTTL with its own TTL.
+type ItemWithTTL struct {
+	Value any
+
+	// TTL is the lifetime of the item. A value of 0 uses the configured TTL.
+	TTL time.Duration
+}
+
+// SetManyWithTTL stores all the given items under a single lock, each one
+// expiring after its own TTL. It suits bulk loads of data with different
+// freshness requirements.
+//
+// Like Preload, it bypasses the metrics accounting, and entries rejected by
+// MaxKeyBytes or MaxValueBytes are skipped. Policies without expiration
+// support store the values and ignore the TTLs.
+func (c *Cache) SetManyWithTTL(items map[string]ItemWithTTL) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	for key, item := range items {
+		key = c.transformKey(key)
+		if !c.accept(key, item.Value) {
+			continue
+		}
+
+		c.setWithDeadline(key, item.Value, c.ttlDeadline(now, item.TTL), unknownSize)
+	}
+}
+
+// setWithDeadline stores a value expiring at deadline, or never if it is zero.
+// size is the size of the value, or unknownSize to measure it with Sizer.
+// The caller must hold the write lock.
+func (c *Cache) setWithDeadline(key string, value any, deadline time.Time, size int) {
+	key = c.internKey(key)
+	c.makeRoom(key)
+	c.ghosts.remove(key)
+	value = c.compress(value)
+	if _, compressed := value.(*compressedValue); compressed {
+		size = unknownSize
+	}
+	c.trackSize(key, value, size)
+	c.bumpVersion(key)
+
+	if c.engine.IsExpirable() {
+		c.engine.SetWithTTL(key, value, deadline)
+		return
+	}
+
+	c.engine.Set(key, value)
+}
+
+// makeRoom evicts an item if key is new and the cache is full.
+// The caller must hold the write lock.
+func (c *Cache) makeRoom(key string) {
+	if c.engine.Has(key) {
+		return
+	}
+
+	if c.config.MaxSize > 0 && c.engine.Len() >= c.config.MaxSize {
+		c.evictOne()
+	}
+}
+
+// evictOne removes a single item, records the eviction and returns the
+// evicted key. The caller must hold the write lock.
+func (c *Cache) evictOne() (string, bool) {
+	key, value, evicted := c.evictUnpinned()
+	if !evicted {
+		return "", false
+	}
+
+	c.recordEviction(key, value)
+	return key, true
+}
+
+// recordEviction updates the bookkeeping of an item removed from the engine
+// by an eviction. The caller must hold the write lock.
+func (c *Cache) recordEviction(key string, value any) {
+	c.forget(key)
+	if c.preloading {
+		return
+	}
+
+	c.removed(value)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.RecordEviction(c.now())
+		c.ghosts.add(key)
+	}
+
+	c.emit(key, ReasonEvicted)
 }
 
 // Delete removes a key-value pair from the cache.
@@ -197,7 +981,61 @@ func (c *Cache) Set(key string, value string) {
 // auxiliary structures (e.g., linked lists for LRU/FIFO or heaps for LFU).
 // If the key does not exist, the function does nothing.
 func (c *Cache) Delete(key string) {
+	c.deleteKey(c.transformKey(key))
+}
+
+// deleteKey removes a transformed key from the cache and the shadow, and
+// reports whether it was in the cache.
+func (c *Cache) deleteKey(key string) bool {
+	if shadow := c.shadow.Load(); shadow != nil {
+		shadow.Delete(key)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	found := c.engine.Has(key)
 	c.engine.Delete(key)
+	c.forget(key)
+	return found
+}
+
+// DeleteFunc removes every entry for which pred returns true and returns the
+// number of entries removed.
+//
+// The whole scan runs under the write lock, so pred sees a consistent view of
+// the cache; it must not call methods of the cache. Expired entries are skipped.
+func (c *Cache) DeleteFunc(pred func(key string, value any) bool) int {
+	matched := c.deleteMatching(pred)
+
+	if shadow := c.shadow.Load(); shadow != nil {
+		for _, key := range matched {
+			shadow.Delete(key)
+		}
+	}
+
+	return len(matched)
+}
+
+// deleteMatching removes and returns the keys for which pred returns true.
+func (c *Cache) deleteMatching(pred func(key string, value any) bool) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var matched []string
+	c.engine.Range(func(key string, value any) bool {
+		if pred(key, c.unwrap(value)) {
+			matched = append(matched, key)
+		}
+		return true
+	})
+
+	for _, key := range matched {
+		c.engine.Delete(key)
+		c.forget(key)
+	}
+
+	return matched
 }
 
 // Has checks whether a given key exists in the cache.
@@ -205,19 +1043,209 @@ func (c *Cache) Delete(key string) {
 // Returns true if the key is present and has not expired (for TTL-based caches).
 // If the key does not exist or has expired, it returns false.
 func (c *Cache) Has(key string) bool {
+	key = c.transformKey(key)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return c.engine.Has(key)
 }
 
 // Len returns the number of items currently stored in the cache.
 //
-// For TTL-based caches, only non-expired items are counted. In other eviction
-// policies (FIFO, LRU, LFU), it returns the total number of stored items.
+// For the policies supporting expiration (Basic and FIFO), expired items are
+// excluded, consistently with Has and Get: a key Has reports as missing is
+// never counted. In other eviction policies, it returns the total number of
+// stored items.
 func (c *Cache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return c.engine.Len()
 }
 
+// Evict removes items from the cache based on the eviction policy.
+//
+// A single call removes up to `EvictBatchSize` items (one by default),
+// stopping early if the cache becomes empty or only pinned keys remain.
 func (c *Cache) Evict() {
-	c.engine.Evict()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.evict(c.evictBatchSize())
+}
+
+// EvictKey removes a single item chosen by the eviction policy and returns its
+// key, e.g. for logging or to invalidate dependent entries. It ignores
+// EvictBatchSize and returns false if the cache is empty or every key is
+// pinned.
+func (c *Cache) EvictKey() (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.evictOne()
+}
+
+// evict removes up to n items from the engine. The caller must hold the write lock.
+func (c *Cache) evict(n int) {
+	for i := 0; i < n && c.engine.Len() > 0; i++ {
+		if _, evicted := c.evictOne(); !evicted {
+			return
+		}
+	}
+}
+
+// evictBatchSize returns the number of items removed by a single Evict call.
+func (c *Cache) evictBatchSize() int {
+	if c.config.EvictBatchSize <= 0 {
+		return 1
+	}
+
+	return c.config.EvictBatchSize
+}
+
+// Keys returns the keys currently stored in the cache.
+//
+// For TTL-based caches, expired keys are not included. The order of the
+// returned keys is not guaranteed.
+func (c *Cache) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.engine.Keys()
+}
+
+// EvictionOrder returns the keys currently stored in the cache, ordered from
+// the next to be evicted to the last.
+//
+// FIFO reports keys by insertion order, LRU from least to most recently used,
+// LFU by ascending frequency (least recently used first among ties), and Basic
+// by expiration time (insertion order among ties, or an order drawn from
+// Config.Rand if set). The order is a snapshot taken under the cache lock.
+//
+// Pinned keys are never evicted, so they are left out. Keys Config.CanEvict
+// vetoes come last, in the same order, since they are only evicted once no
+// other key is left; CanEvict is called for every unpinned key to tell them
+// apart.
+func (c *Cache) EvictionOrder() []string {
+	// CanEvict is only ever called under the write lock, so it need not be
+	// safe for concurrent use.
+	if c.config.CanEvict != nil {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+	} else {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+	}
+
+	keys := c.engine.Keys()
+	if len(c.pinned) == 0 && c.config.CanEvict == nil {
+		return keys
+	}
+
+	order := keys[:0]
+	var vetoed []string
+	for _, key := range keys {
+		if _, pinned := c.pinned[key]; pinned {
+			continue
+		}
+
+		value, _, found := c.engine.Peek(key)
+		if found && !c.canEvict(key, value) {
+			vetoed = append(vetoed, key)
+			continue
+		}
+		order = append(order, key)
+	}
+
+	return append(order, vetoed...)
+}
+
+// Clear removes all items from the cache.
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.engine.Clear()
+	c.forgetAll()
+}
+
+// Drain removes all items from the cache and returns them, e.g. to hand them
+// over to another process on shutdown.
+//
+// The snapshot and the removal happen under a single lock, so no write is
+// lost between them. Expired entries are not returned.
+func (c *Cache) Drain() map[string]any {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	items := make(map[string]any, c.engine.Len())
+	c.engine.Range(func(key string, value any) bool {
+		items[key] = c.unwrap(value)
+		return true
+	})
+
+	c.engine.Clear()
+	c.forgetAll()
+
+	return items
+}
+
+// Close stops the background goroutines of the cache, such as the memory
+// check and the cleanup of expired items. It is safe to call more than once.
+//
+// The cache stays readable and writable after Close, but GetE, SetE and
+// DeleteE return ErrClosed.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		c.closed.Store(true)
+		close(c.done)
+
+		c.lock.RLock()
+		e := c.engine
+		c.lock.RUnlock()
+
+		if closer, ok := e.(engine.Closer); ok {
+			closer.Close()
+		}
+
+		c.DetachShadow()
+	})
+}
+
+// Policy returns the current eviction policy of the cache: the configured one,
+// or the last one set with SwitchPolicy.
+func (c *Cache) Policy() EvictionPolicy {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.policy
+}
+
+// IsExpirable reports whether the current eviction policy supports TTL-based
+// expiration: true for Basic and FIFO, false for the other policies, which
+// ignore TTLs.
+func (c *Cache) IsExpirable() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.engine.IsExpirable()
+}
+
+// EnableMetrics turns metrics collection on or off at runtime, overriding
+// Config.Metrics. Counts collected so far are kept; use ResetMetrics to clear them.
+func (c *Cache) EnableMetrics(enabled bool) {
+	c.metricsEnabled.Store(enabled)
+}
+
+// MetricsEnabled reports whether metrics are currently being collected.
+func (c *Cache) MetricsEnabled() bool {
+	return c.metricsEnabled.Load()
+}
+
+// ResetMetrics sets all the cache metrics back to zero (see Metrics.Reset).
+func (c *Cache) ResetMetrics() {
+	c.metrics.Reset()
 }
 
 // Metrics returns a pointer to the cache's metrics instance.
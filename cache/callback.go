@@ -0,0 +1,16 @@
+package cache
+
+// callback calls a user-supplied function. If Config.OnCallbackPanic is set,
+// a panic in fn is recovered and passed to it, so a faulty callback cannot
+// crash the caller or a background goroutine.
+func (c *Cache) callback(fn func()) {
+	if c.config.OnCallbackPanic != nil {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				c.config.OnCallbackPanic(recovered)
+			}
+		}()
+	}
+
+	fn()
+}
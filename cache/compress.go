@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// defaultCompressMinBytes is the size above which values are compressed when
+// Config.CompressMinBytes is not set.
+const defaultCompressMinBytes = 1024
+
+// compressedValue holds a gzip-compressed string or []byte value.
+type compressedValue struct {
+	data     []byte
+	isString bool
+}
+
+// compress returns a compressed version of value if compression is enabled and
+// value is a string or []byte larger than the threshold. Otherwise, or if
+// compression does not reduce the size, value is returned unchanged.
+func (c *Cache) compress(value any) any {
+	if !c.config.Compress {
+		return value
+	}
+
+	minBytes := c.config.CompressMinBytes
+	if minBytes <= 0 {
+		minBytes = defaultCompressMinBytes
+	}
+
+	// The size is checked before converting a string, so values too small to
+	// be compressed are not copied.
+	var raw []byte
+	isString := false
+
+	switch v := value.(type) {
+	case string:
+		if len(v) < minBytes {
+			return value
+		}
+		raw = []byte(v)
+		isString = true
+	case []byte:
+		if len(v) < minBytes {
+			return value
+		}
+		raw = v
+	default:
+		return value
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(raw); err != nil {
+		return value
+	}
+	if err := w.Close(); err != nil {
+		return value
+	}
+
+	if buf.Len() >= len(raw) {
+		return value
+	}
+
+	return &compressedValue{data: buf.Bytes(), isString: isString}
+}
+
+// decompress returns the original value of a compressed one. Values that were
+// not compressed are returned unchanged.
+func (c *Cache) decompress(value any) any {
+	compressed, ok := value.(*compressedValue)
+	if !ok {
+		return value
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed.data))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+
+	if compressed.isString {
+		return string(raw)
+	}
+
+	return raw
+}
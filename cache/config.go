@@ -1,36 +1,328 @@
 package cache
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// ErrInvalidConfig is returned by Config.Validate when a setting is invalid.
+// The returned error wraps it with a description of the offending field.
+var ErrInvalidConfig = errors.New("easycache: invalid config")
 
 // Config defines the configuration settings for the cache.
 //
 // This struct allows customization of eviction policies, memory limits, TTL,
 // and other performance-related parameters.
 type Config struct {
-	// EvictionPolicy determines the cache's item removal strategy (FIFO, LRU, LFU, or Basic).
+	// EvictionPolicy determines the cache's item removal strategy (FIFO, LRU, LFU, LRUK, Clock, or Basic).
 	EvictionPolicy EvictionPolicy
 
+	// CustomEngine, if set, creates the engine of the cache instead of the
+	// built-in one of EvictionPolicy, so a cache can use an eviction policy
+	// defined outside this package. It is called once by New with MaxSize.
+	// The cache still evicts through the engine once MaxSize is reached, and
+	// uses the optional interfaces of package engine the engine implements.
+	// InitialCapacity is passed to it through engine.Reserver. Policy
+	// reports Custom, and SwitchPolicy replaces the custom engine with a
+	// built-in one.
+	CustomEngine func(maxSize int) engine.Engine
+
+	// LRUK sets the number of references tracked per item by the LRUK policy.
+	// A value of 0 uses the default of 2.
+	LRUK int
+
 	// MaxSize defines the maximum number of items the cache can hold before evicting entries.
 	// A value of 0 means there is no limit.
 	MaxSize int
 
+	// InitialCapacity preallocates the cache for about this many items, which
+	// avoids repeatedly growing the key index while a large cache fills up.
+	// A value of 0 starts empty.
+	InitialCapacity int
+
 	// TTL (Time-To-Live) specifies the duration before an item expires.
 	// If set to 0, items will not expire automatically.
 	TTL time.Duration
 
+	// PreserveTTLOnUpdate makes Set on an existing key keep its current
+	// expiration instead of resetting it to TTL from now, so frequent updates
+	// don't keep an entry alive forever. Writes with an explicit expiration,
+	// such as SetWithDeadline, still replace it.
+	PreserveTTLOnUpdate bool
+
+	// MinTTL is the shortest lifetime an expiring item can get: shorter TTLs,
+	// whether configured or passed to SetNX, SetManyWithTTL,
+	// SetWithExpireCallback or Touch, and deadlines closer than MinTTL passed
+	// to SetWithDeadline are raised to it, so that a tiny or zero TTL computed
+	// by the caller doesn't expire entries before they are ever read. A TTL
+	// of 0 still means no expiration where it does, such as for TTL, and a
+	// zero deadline still means never. A value of 0 disables the floor.
+	MinTTL time.Duration
+
+	// RefreshAheadLead is how long before their expiration the keys
+	// registered with Cache.RegisterRefresher are reloaded. A value of 0 uses
+	// a tenth of TTL.
+	RefreshAheadLead time.Duration
+
+	// MaxIdle expires items that have not been read or written for longer than
+	// MaxIdle, on top of their TTL: an item expires when either limit is
+	// reached. It only applies to the Basic policy. A value of 0 disables it.
+	MaxIdle time.Duration
+
+	// StaleGrace keeps expired items for this long past their expiration, so
+	// Cache.GetStale can serve them while a fresh value is computed
+	// (stale-while-revalidate). Other reads still treat them as expired. It
+	// only applies to the Basic policy. A value of 0 disables it.
+	StaleGrace time.Duration
+
 	// CleanupInterval defines how often expired items are removed from the cache.
 	// This is only applicable if TTL-based expiration is enabled.
 	CleanupInterval time.Duration
 
+	// MinCleanupInterval and MaxCleanupInterval make the cleanup interval of
+	// the Basic policy adaptive. Starting from CleanupInterval, it is halved,
+	// down to MinCleanupInterval, after a cleanup that finds many expired
+	// items, and doubled, up to MaxCleanupInterval, after one that finds none.
+	// This sweeps more often during bursts of expirations and saves CPU while
+	// idle. A bound left at 0 is CleanupInterval, so by default the interval
+	// is fixed.
+	MinCleanupInterval time.Duration
+	MaxCleanupInterval time.Duration
+
+	// CleanupBatchFraction is the fraction of the keys swept for expired items
+	// on each cleanup tick, between 0 and 1. Sweeping a slice of the keys per
+	// tick bounds how long the cleanup blocks reads and writes on large caches.
+	// A value of 0 or 1 sweeps the whole cache on every tick.
+	CleanupBatchFraction float64
+
+	// AutoShrink makes the periodic cleanup rebuild the key index of the Basic
+	// policy into a smaller map once most of its items are gone. Go maps never
+	// shrink, so without it a cache that spiked and then drained keeps the
+	// memory of its peak. Rebuilding copies the remaining items under the lock.
+	AutoShrink bool
+
+	// LazyExpiryDelete makes a read of an expired key delete it right away.
+	// By default, the read only reports a miss and the key is reclaimed later,
+	// by the periodic cleanup of Basic or the next insertion into FIFO, so the
+	// read path never takes the write lock.
+	LazyExpiryDelete bool
+
+	// EarlyRecompute enables probabilistic early expiration (XFetch) to avoid
+	// cache stampedes on keys stored with SetWithComputeTime: as such a key gets
+	// close to its expiration, Get reports it as missing to a growing fraction
+	// of callers, so one of them recomputes it while the others are still
+	// served. Higher values recompute earlier; 1 is the recommended value and 0
+	// disables it.
+	EarlyRecompute float64
+
+	// Rand, if set, is the source of the random choices of the cache instead
+	// of the global source: the early recomputations of EarlyRecompute, and
+	// the order in which the Basic policy evicts items expiring at the same
+	// time, which is otherwise their insertion order. Seeding it makes these
+	// choices reproducible in tests.
+	Rand *rand.Rand
+
 	// MemoryLimits specifies the maximum memory usage (in bytes) before triggering cache cleanup.
 	// A value of 0 means memory usage is not restricted.
 	MemoryLimits uint64
 
+	// MemoryHighWatermark and MemoryLowWatermark add hysteresis to the memory
+	// check, so a usage hovering around a single limit doesn't evict a few
+	// items on every check. Eviction starts once the usage exceeds the high
+	// watermark and goes on, over later checks if needed, until it drops to
+	// the low watermark. The high watermark defaults to MemoryLimits and the
+	// low one to the high one, which evicts down to MemoryLimits as before.
+	MemoryHighWatermark uint64
+	MemoryLowWatermark  uint64
+
+	// MemoryEviction selects the items evicted when the memory usage exceeds
+	// the limits: by the eviction policy (the default), or the largest first.
+	MemoryEviction MemoryEviction
+
 	// MemoryCheckInterval sets the frequency at which memory usage is checked.
 	MemoryCheckInterval time.Duration
 
+	// MemoryUsage reports the current memory usage in bytes, compared against
+	// MemoryLimits. If nil, Cache.EstimatedBytes is used with EstimateSize, and
+	// the heap allocation of the process (`runtime.MemStats.Alloc`) otherwise.
+	// It is called from the memory check goroutine without the cache lock
+	// held, so it may read the cache, e.g. its metrics.
+	MemoryUsage func() uint64
+
+	// LFUScore computes the eviction score of an item from its access frequency
+	// and weight (see Cache.SetWeighted) for the LFU policy. Items with the lowest
+	// score are evicted first. Setting it switches LFU to a heap-based engine
+	// with O(log n) accesses. If nil, the O(1) LFU engine is used until
+	// SetWeighted is first called with a weight other than 1, which switches
+	// to the heap-based engine with lfu.DefaultScore (frequency * weight).
+	LFUScore func(frequency int, weight float64) float64
+
+	// LFUApproxCounters makes the LFU policy estimate access frequencies with a
+	// fixed-size count-min sketch instead of an exact counter per item, bounding
+	// the frequency metadata at the cost of some eviction accuracy. The sketch
+	// is sized for the larger of MaxSize and InitialCapacity keys, and for at
+	// least 65536 keys when MaxSize is 0. Each stored item still keeps its own
+	// entry, so only the metadata of evicted keys is bounded. It cannot be
+	// combined with LFUScore.
+	LFUApproxCounters bool
+
+	// LFUMaxFrequency is the ceiling at which the access frequency of an item
+	// stops increasing with the LFU policy, so counters of long-lived hot keys
+	// cannot overflow. Items at the ceiling are evicted least recently used
+	// first. A value of 0 uses lfu.DefaultMaxFrequency.
+	LFUMaxFrequency int
+
+	// LFUCountWindow debounces the access frequencies of the LFU policy:
+	// repeated accesses to an item within LFUCountWindow of its last counted
+	// access only refresh its recency, so a burst of accesses counts once
+	// instead of permanently inflating its frequency. A value of 0 counts
+	// every access.
+	LFUCountWindow time.Duration
+
+	// LFUOnCorruption, if set, is called when the heap of the LFU policy with
+	// LFUScore is found corrupted and rebuilt from the stored items, with
+	// lfu.ErrHeapCorrupted or the value recovered from the failing heap
+	// operation. It is called with the cache lock held, so it must not call
+	// methods of the cache.
+	LFUOnCorruption func(recovered any)
+
+	// EvictBatchSize defines how many items a single call to Evict removes.
+	// A value of 0 or 1 removes exactly one item. The memory-pressure check
+	// evicts a larger batch of EvictBatchSize * memoryEvictBatchFactor items.
+	EvictBatchSize int
+
+	// CanEvict, if set, is consulted before evicting an entry, to make room or
+	// on Evict, e.g. to keep entries that are in use. When it returns false, the next
+	// candidate in eviction order is tried instead. If it vetoes every
+	// candidate, the first one is evicted anyway, so the cache never grows
+	// past MaxSize because of it; use Cache.Pin to exempt a key for good.
+	// Cache.EvictionOrder calls it too, for every unpinned key, to list the
+	// vetoed keys last. It is called with the cache lock held, so it must not
+	// call methods of the cache. A panic in it is treated like a true result when
+	// OnCallbackPanic is set.
+	CanEvict func(key string, value any) bool
+
+	// Compress enables transparent gzip compression of string and []byte values
+	// larger than CompressMinBytes. Values are compressed on Set and decompressed on Get.
+	Compress bool
+
+	// CompressMinBytes is the minimum size of a value to be compressed.
+	// A value of 0 uses a default of 1024 bytes.
+	CompressMinBytes int
+
+	// CopyOnGet makes reads (Get, GetE, GetAs, GetAndDelete, LoadOrStore)
+	// return a copy of the cached value made by CopyFunc, so callers mutating a
+	// returned slice or map do not corrupt the value seen by other readers. It
+	// costs an allocation and a copy proportional to the value size on every
+	// hit, so it is best left off for large values that are never mutated.
+	CopyOnGet bool
+
+	// CopyFunc copies values for CopyOnGet. If nil, ShallowCopy is used, which
+	// copies slices and maps but not their elements; set it to deep copy nested
+	// or pointer values.
+	CopyFunc CopyFunc
+
+	// KeyTransform, if set, is applied to every key passed to the cache before
+	// it is stored or looked up, e.g. strings.ToLower for case-insensitive keys
+	// or a hash to bound the memory of long keys. Keys that transform to the
+	// same string share an entry. Keys, EvictionOrder, Scan and the events
+	// report transformed keys, and MaxKeyBytes applies to the transformed key.
+	KeyTransform func(key string) string
+
+	// InternKeys stores keys through a process-wide intern pool (see package
+	// unique), so that identical key strings share a single copy, and a key
+	// sliced out of a larger string, such as a request body, doesn't keep it
+	// alive for as long as the entry is cached. The cache holds a pool handle
+	// for every stored key, so the copy outlives garbage collections while
+	// the entry is cached. It adds a pool lookup to every write of a key.
+	InternKeys bool
+
+	// MaxKeyBytes is the maximum length of a key. Writes with longer keys are
+	// dropped and counted by Cache.RejectedSets. A value of 0 means no limit.
+	MaxKeyBytes int
+
+	// MaxValueBytes is the maximum size of a value, as measured by Sizer.
+	// Writes with larger values are dropped and counted by Cache.RejectedSets.
+	// A value of 0 means no limit.
+	MaxValueBytes int
+
+	// Sizer returns the size in bytes of a value. If nil, DefaultSizer is used.
+	Sizer Sizer
+
+	// SkipIfEqual makes Set a no-op when the key already holds an unexpired
+	// value equal to the new one, so idempotent refreshes do not change the
+	// eviction order (recency, frequency) nor extend the expiration.
+	SkipIfEqual bool
+
+	// EqualFunc compares the stored value with the new one for SkipIfEqual. If
+	// nil, reflect.DeepEqual is used. It is called with the cache lock held, so
+	// it must not call methods of the cache.
+	EqualFunc func(stored, value any) bool
+
+	// EstimateSize keeps track of the size of every entry, as measured by
+	// Sizer or by its compressed size with Compress, and reports their sum in
+	// Cache.EstimatedBytes. It adds a Sizer call and a map update to every
+	// write. When MemoryLimits is set without a MemoryUsage function, the
+	// estimate is compared against MemoryLimits instead of the heap
+	// allocation of the whole process.
+	EstimateSize bool
+
+	// EvictionGhostSize is the number of recently evicted keys remembered to
+	// count Metrics.EvictionRegret, the misses on keys evicted shortly before.
+	// Only their keys are kept. A value of 0 uses MaxSize, or 1024 without one.
+	EvictionGhostSize int
+
+	// MetricsLogger, if set, is called with the stats of the cache every
+	// MetricsLogInterval from a background goroutine, e.g. to log them or
+	// forward them to a monitoring system. It stops once the cache is closed.
+	// It is called without the cache lock held.
+	MetricsLogger func(Stats)
+
+	// MetricsLogInterval sets how often MetricsLogger is called. A value of 0
+	// disables it.
+	MetricsLogInterval time.Duration
+
+	// MaxLoadWaiters bounds the number of callers of GetOrSet waiting for the
+	// value of a key while another caller loads it. Further callers get
+	// ErrTooManyWaiters instead of queuing, which bounds the goroutines piling
+	// up behind a slow loader. A value of 0 means no limit.
+	MaxLoadWaiters int
+
+	// EventBufferSize sets the capacity of the channel returned by Cache.Events.
+	// A value of 0 uses a default of 256 events.
+	EventBufferSize int
+
 	// Metrics indicates whether cache statistics (hits, misses, evictions) should be collected.
 	Metrics bool
+
+	// OnHit, if set, is called by Get with the key on every cache hit.
+	// It is called outside the cache lock, so it may use the cache.
+	OnHit func(key string)
+
+	// OnMiss, if set, is called by Get with the key on every cache miss,
+	// including expired keys. It is called outside the cache lock, so it may use the cache.
+	OnMiss func(key string)
+
+	// OnCallbackPanic, if set, receives the value of a panic raised by a
+	// user-supplied callback (OnHit, OnMiss, MemoryUsage, or a loader run in
+	// the background by GetOrRefresh or RegisterRefresher). The panic is
+	// recovered, so the cache and its background goroutines keep running.
+	// It may be called with the cache lock held, so it must not call methods of
+	// the cache. If nil, panics are not recovered.
+	OnCallbackPanic func(recovered any)
+
+	// LatencyMetrics enables latency histograms for Get and Set calls, reported by
+	// Metrics.GetLatency and Metrics.SetLatency. It adds two clock reads per call.
+	LatencyMetrics bool
+
+	// Clock returns the current time, used for latency measurements, item
+	// expiration and the access times of LastAccess. If nil, time.Now is used.
+	// It is mainly useful to control time in tests.
+	Clock func() time.Time
 }
 
 // defaultConfig returns a Config with default settings.
@@ -48,3 +340,104 @@ func defaultConfig() *Config {
 		Metrics:             false,
 	}
 }
+
+// Clone returns a copy of the configuration.
+//
+// The cache keeps its own copy of the Config it was created with, so mutating
+// the caller's struct afterwards does not affect a running cache.
+func (cfg *Config) Clone() *Config {
+	clone := *cfg
+	return &clone
+}
+
+// memoryHighWatermark returns the memory usage above which eviction starts.
+func (cfg *Config) memoryHighWatermark() uint64 {
+	if cfg.MemoryHighWatermark > 0 {
+		return cfg.MemoryHighWatermark
+	}
+
+	return cfg.MemoryLimits
+}
+
+// memoryLowWatermark returns the memory usage down to which eviction goes on.
+func (cfg *Config) memoryLowWatermark() uint64 {
+	if cfg.MemoryLowWatermark > 0 {
+		return cfg.MemoryLowWatermark
+	}
+
+	return cfg.memoryHighWatermark()
+}
+
+// Validate checks the configuration for settings that would otherwise
+// silently misbehave, such as negative sizes or durations, an unknown eviction
+// policy, or a memory limit without a check interval.
+func (cfg *Config) Validate() error {
+	switch {
+	case cfg.EvictionPolicy.String() == "unknown":
+		return fmt.Errorf("%w: unknown eviction policy %d", ErrInvalidConfig, cfg.EvictionPolicy)
+	case cfg.EvictionPolicy == Custom && cfg.CustomEngine == nil:
+		return fmt.Errorf("%w: the custom eviction policy requires a CustomEngine", ErrInvalidConfig)
+	case cfg.MaxSize < 0:
+		return fmt.Errorf("%w: MaxSize must not be negative", ErrInvalidConfig)
+	case cfg.InitialCapacity < 0:
+		return fmt.Errorf("%w: InitialCapacity must not be negative", ErrInvalidConfig)
+	case cfg.TTL < 0:
+		return fmt.Errorf("%w: TTL must not be negative", ErrInvalidConfig)
+	case cfg.MinTTL < 0:
+		return fmt.Errorf("%w: MinTTL must not be negative", ErrInvalidConfig)
+	case cfg.RefreshAheadLead < 0:
+		return fmt.Errorf("%w: RefreshAheadLead must not be negative", ErrInvalidConfig)
+	case cfg.MaxIdle < 0:
+		return fmt.Errorf("%w: MaxIdle must not be negative", ErrInvalidConfig)
+	case cfg.StaleGrace < 0:
+		return fmt.Errorf("%w: StaleGrace must not be negative", ErrInvalidConfig)
+	case cfg.CleanupInterval < 0:
+		return fmt.Errorf("%w: CleanupInterval must not be negative", ErrInvalidConfig)
+	case cfg.MinCleanupInterval < 0:
+		return fmt.Errorf("%w: MinCleanupInterval must not be negative", ErrInvalidConfig)
+	case cfg.MaxCleanupInterval < 0:
+		return fmt.Errorf("%w: MaxCleanupInterval must not be negative", ErrInvalidConfig)
+	case cfg.MinCleanupInterval > 0 && cfg.MaxCleanupInterval > 0 && cfg.MinCleanupInterval > cfg.MaxCleanupInterval:
+		return fmt.Errorf("%w: MinCleanupInterval must not exceed MaxCleanupInterval", ErrInvalidConfig)
+	case cfg.CleanupBatchFraction < 0 || cfg.CleanupBatchFraction > 1:
+		return fmt.Errorf("%w: CleanupBatchFraction must be between 0 and 1", ErrInvalidConfig)
+	case cfg.MemoryEviction < MemoryEvictByPolicy || cfg.MemoryEviction > MemoryEvictLargest:
+		return fmt.Errorf("%w: unknown MemoryEviction %d", ErrInvalidConfig, cfg.MemoryEviction)
+	case cfg.MemoryCheckInterval < 0:
+		return fmt.Errorf("%w: MemoryCheckInterval must not be negative", ErrInvalidConfig)
+	case cfg.MemoryLimits > 0 && cfg.MemoryCheckInterval == 0:
+		return fmt.Errorf("%w: MemoryLimits requires a MemoryCheckInterval", ErrInvalidConfig)
+	case cfg.MemoryHighWatermark > 0 && cfg.MemoryCheckInterval == 0:
+		return fmt.Errorf("%w: MemoryHighWatermark requires a MemoryCheckInterval", ErrInvalidConfig)
+	case cfg.MemoryLowWatermark > 0 && cfg.MemoryHighWatermark == 0 && cfg.MemoryLimits == 0:
+		return fmt.Errorf("%w: MemoryLowWatermark requires MemoryLimits or MemoryHighWatermark", ErrInvalidConfig)
+	case cfg.MemoryLowWatermark > cfg.memoryHighWatermark():
+		return fmt.Errorf("%w: MemoryLowWatermark must not exceed the high watermark", ErrInvalidConfig)
+	case cfg.LRUK < 0:
+		return fmt.Errorf("%w: LRUK must not be negative", ErrInvalidConfig)
+	case cfg.LFUScore != nil && cfg.LFUApproxCounters:
+		return fmt.Errorf("%w: LFUScore and LFUApproxCounters cannot be combined", ErrInvalidConfig)
+	case cfg.LFUMaxFrequency < 0:
+		return fmt.Errorf("%w: LFUMaxFrequency must not be negative", ErrInvalidConfig)
+	case cfg.LFUCountWindow < 0:
+		return fmt.Errorf("%w: LFUCountWindow must not be negative", ErrInvalidConfig)
+	case cfg.EvictBatchSize < 0:
+		return fmt.Errorf("%w: EvictBatchSize must not be negative", ErrInvalidConfig)
+	case cfg.MaxKeyBytes < 0:
+		return fmt.Errorf("%w: MaxKeyBytes must not be negative", ErrInvalidConfig)
+	case cfg.MaxValueBytes < 0:
+		return fmt.Errorf("%w: MaxValueBytes must not be negative", ErrInvalidConfig)
+	case cfg.EarlyRecompute < 0:
+		return fmt.Errorf("%w: EarlyRecompute must not be negative", ErrInvalidConfig)
+	case cfg.EvictionGhostSize < 0:
+		return fmt.Errorf("%w: EvictionGhostSize must not be negative", ErrInvalidConfig)
+	case cfg.MetricsLogInterval < 0:
+		return fmt.Errorf("%w: MetricsLogInterval must not be negative", ErrInvalidConfig)
+	case cfg.MaxLoadWaiters < 0:
+		return fmt.Errorf("%w: MaxLoadWaiters must not be negative", ErrInvalidConfig)
+	case cfg.EventBufferSize < 0:
+		return fmt.Errorf("%w: EventBufferSize must not be negative", ErrInvalidConfig)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import "reflect"
+
+// CopyFunc returns a copy of a cached value, handed to readers instead of the
+// stored value when Config.CopyOnGet is set.
+type CopyFunc func(value any) any
+
+// ShallowCopy copies slices and maps into new ones holding the same elements,
+// so callers can add, remove or replace elements without affecting the cache.
+// The elements themselves are not copied, and other values, including
+// pointers, are returned unchanged.
+func ShallowCopy(value any) any {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		return copied.Interface()
+	case reflect.Map:
+		if v.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return copied.Interface()
+	default:
+		return value
+	}
+}
+
+// copyValue returns the copy of value to hand to a reader if CopyOnGet is
+// set, and value itself otherwise.
+func (c *Cache) copyValue(value any) any {
+	if !c.config.CopyOnGet {
+		return value
+	}
+
+	if c.config.CopyFunc != nil {
+		return c.config.CopyFunc(value)
+	}
+
+	return ShallowCopy(value)
+}
@@ -0,0 +1,53 @@
+package cache
+
+import "sync"
+
+var (
+	// defaultCache is the package-level cache used by Get, Set and Delete.
+	defaultCache *Cache
+
+	// defaultOnce guards the lazy initialization of defaultCache.
+	defaultOnce sync.Once
+)
+
+// Configure sets the configuration used to build the package-level default cache.
+//
+// It only takes effect if called before the first use of the default cache
+// (through Default, Get, Set or Delete). Later calls are ignored and return false,
+// since the default cache is created only once.
+func Configure(cfg *Config) bool {
+	configured := false
+	defaultOnce.Do(func() {
+		defaultCache = New(cfg)
+		configured = true
+	})
+
+	return configured
+}
+
+// Default returns the package-level default cache.
+//
+// The cache is created lazily on first use. Unless Configure was called before,
+// it is built with the default configuration (Basic policy with a 60 seconds TTL).
+func Default() *Cache {
+	defaultOnce.Do(func() {
+		defaultCache = New(nil)
+	})
+
+	return defaultCache
+}
+
+// Get retrieves a value from the default cache by its key.
+func Get(key string) (any, bool) {
+	return Default().Get(key)
+}
+
+// Set stores a key-value pair in the default cache.
+func Set(key string, value any) {
+	Default().Set(key, value)
+}
+
+// Delete removes a key-value pair from the default cache.
+func Delete(key string) {
+	Default().Delete(key)
+}
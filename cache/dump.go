@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dumpMaxValueLen is the maximum length of a formatted value in Dump.
+const dumpMaxValueLen = 64
+
+// Dump returns a human-readable listing of the cache contents, meant for debugging.
+//
+// The output starts with the policy and length, followed by one line per key in
+// eviction order (next to be evicted first) with the value type, the value
+// truncated to 64 characters and, for TTL-based caches, the remaining TTL.
+// The listing is a snapshot taken under the cache lock.
+func (c *Cache) Dump() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := c.engine.Keys()
+	now := c.now()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "policy=%s len=%d\n", c.policy, len(keys))
+
+	for i, key := range keys {
+		value, expiresAt, exists := c.engine.Peek(key)
+		if !exists {
+			continue
+		}
+		value = c.unwrap(value)
+
+		fmt.Fprintf(&b, "%d. %q (%T) = %s", i+1, key, value, truncate(fmt.Sprintf("%v", value)))
+		if !expiresAt.IsZero() {
+			fmt.Fprintf(&b, " ttl=%s", expiresAt.Sub(now).Round(time.Millisecond))
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// truncate shortens s to dumpMaxValueLen characters, marking the cut with "...".
+func truncate(s string) string {
+	runes := []rune(s)
+	if len(runes) <= dumpMaxValueLen {
+		return s
+	}
+
+	return string(runes[:dumpMaxValueLen]) + "..."
+}
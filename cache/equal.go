@@ -0,0 +1,21 @@
+package cache
+
+import "reflect"
+
+// unchanged reports whether key holds an unexpired value equal to value, as
+// decided by Config.EqualFunc, or reflect.DeepEqual if it is nil. It uses Peek,
+// so the check itself does not touch the eviction order. The caller must hold
+// the lock.
+func (c *Cache) unchanged(key string, value any) bool {
+	stored, _, exists := c.engine.Peek(key)
+	if !exists || (c.engine.IsExpirable() && c.engine.IsExpired(key)) {
+		return false
+	}
+
+	equal := c.config.EqualFunc
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+
+	return equal(c.unwrap(stored), value)
+}
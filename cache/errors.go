@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrClosed is returned by operations on a cache that has been closed.
+	ErrClosed = errors.New("easycache: cache is closed")
+
+	// ErrNotFound is returned when a key is not in the cache or has expired.
+	ErrNotFound = errors.New("easycache: key not found")
+
+	// ErrWrongType is returned by GetAs when the cached value does not have the
+	// requested type. The returned error wraps it with the actual type.
+	ErrWrongType = errors.New("easycache: wrong value type")
+
+	// ErrTooLarge is returned by SetE when the key or value exceeds
+	// Config.MaxKeyBytes or Config.MaxValueBytes.
+	ErrTooLarge = errors.New("easycache: entry too large")
+
+	// ErrTooManyWaiters is returned by GetOrSet when Config.MaxLoadWaiters
+	// callers are already waiting for the value of the key to be loaded.
+	ErrTooManyWaiters = errors.New("easycache: too many waiters for the key")
+
+	// ErrLoadPanicked is returned by GetOrSet to the callers waiting for a
+	// load that panicked. The returned error wraps it with the panic value.
+	ErrLoadPanicked = errors.New("easycache: load panicked")
+)
+
+// GetE is like Get, but reports a miss with ErrNotFound and returns ErrClosed
+// once the cache is closed.
+func (c *Cache) GetE(key string) (any, error) {
+	if c.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	value, found := c.Get(key)
+	if !found {
+		return nil, ErrNotFound
+	}
+
+	return value, nil
+}
+
+// SetE is like Set, but returns ErrTooLarge if the entry is rejected by the
+// size limits and ErrClosed once the cache is closed.
+func (c *Cache) SetE(key string, value any) error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	if !c.store(key, value, unknownSize) {
+		return ErrTooLarge
+	}
+
+	return nil
+}
+
+// DeleteE is like Delete, but returns ErrNotFound if the key is not in the
+// cache and ErrClosed once the cache is closed.
+func (c *Cache) DeleteE(key string) error {
+	key = c.transformKey(key)
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	if !c.deleteKey(key) {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetAs returns the value stored under key as a T. It returns ErrWrongType if
+// the value has another type, and the errors of GetE otherwise.
+func GetAs[T any](c *Cache, key string) (T, error) {
+	var zero T
+
+	value, err := c.GetE(key)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: got %T, want %T", ErrWrongType, value, zero)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+// EvictReason describes why an item was removed from the cache.
+type EvictReason int
+
+const (
+	// ReasonExpired means the item was removed because its TTL elapsed.
+	ReasonExpired EvictReason = iota
+
+	// ReasonEvicted means the item was removed by the eviction policy.
+	ReasonEvicted
+)
+
+// defaultEventBufferSize is the capacity of the events channel when
+// Config.EventBufferSize is not set.
+const defaultEventBufferSize = 256
+
+// String returns the lowercase name of the reason, such as "expired".
+func (r EvictReason) String() string {
+	switch r {
+	case ReasonExpired:
+		return "expired"
+	case ReasonEvicted:
+		return "evicted"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a notification sent when an item leaves the cache on its own,
+// either because it expired or because it was evicted.
+type Event struct {
+	Key    string
+	Reason EvictReason
+}
+
+// Events returns a channel receiving an Event whenever a key expires or is evicted.
+//
+// The channel is created on the first call and shared by all callers. It is
+// buffered (see `Config.EventBufferSize`); if the consumer falls behind, new
+// events are dropped rather than blocking the cache, and counted in DroppedEvents.
+func (c *Cache) Events() <-chan Event {
+	c.eventsOnce.Do(func() {
+		size := c.config.EventBufferSize
+		if size <= 0 {
+			size = defaultEventBufferSize
+		}
+
+		c.events = make(chan Event, size)
+		c.eventsEnabled.Store(true)
+	})
+
+	return c.events
+}
+
+// DroppedEvents returns the number of events discarded because the events
+// channel was full.
+func (c *Cache) DroppedEvents() uint64 {
+	return c.droppedEvents.Load()
+}
+
+// emit sends an event without blocking. It does nothing until Events is called.
+func (c *Cache) emit(key string, reason EvictReason) {
+	if !c.eventsEnabled.Load() {
+		return
+	}
+
+	select {
+	case c.events <- Event{Key: key, Reason: reason}:
+	default:
+		c.droppedEvents.Add(1)
+	}
+}
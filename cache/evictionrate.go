@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// evictionWindowSeconds is the span covered by an evictionWindow.
+const evictionWindowSeconds = 60
+
+// evictionWindow counts evictions over the last minute.
+//
+// It is a ring buffer of one-second slots: each slot holds the second it
+// belongs to and the evictions recorded during it. A slot is reused once its
+// second falls out of the window, so the memory used is fixed regardless of
+// the eviction rate.
+type evictionWindow struct {
+	lock   sync.Mutex
+	second [evictionWindowSeconds]int64
+	counts [evictionWindowSeconds]int64
+}
+
+func (w *evictionWindow) record(at time.Time) {
+	s := at.Unix()
+	i := evictionSlot(s)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.second[i] != s {
+		w.second[i] = s
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
+
+// count returns the evictions recorded in the minute before now.
+func (w *evictionWindow) count(now time.Time) int64 {
+	s := now.Unix()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var total int64
+	for i := range w.counts {
+		if age := s - w.second[i]; age >= 0 && age < evictionWindowSeconds {
+			total += w.counts[i]
+		}
+	}
+
+	return total
+}
+
+func (w *evictionWindow) reset() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.second = [evictionWindowSeconds]int64{}
+	w.counts = [evictionWindowSeconds]int64{}
+}
+
+func evictionSlot(second int64) int {
+	i := second % evictionWindowSeconds
+	if i < 0 {
+		i += evictionWindowSeconds
+	}
+
+	return int(i)
+}
@@ -0,0 +1,49 @@
+package cache
+
+import "time"
+
+// callbackValue holds a value stored with SetWithExpireCallback along with
+// its callback.
+type callbackValue struct {
+	value    any
+	onRemove func(value any)
+}
+
+// SetWithExpireCallback stores a value expiring after ttl, like Set with a
+// per-entry TTL, and calls cb with the value once the entry expires or is
+// evicted, e.g. to release a resource tied to it. A ttl of 0 uses the
+// configured TTL, and policies without expiration support only call cb on
+// eviction.
+//
+// cb is not called when the entry is deleted or replaced by another Set. It
+// may be called with the cache lock held, so it must not call methods of the
+// cache.
+func (c *Cache) SetWithExpireCallback(key string, value any, ttl time.Duration, cb func(value any)) {
+	key = c.transformKey(key)
+
+	if !c.accept(key, value) {
+		return
+	}
+
+	deadline := c.ttlDeadline(c.now(), ttl)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setWithDeadline(key, &callbackValue{value: c.compress(value), onRemove: cb}, deadline, unknownSize)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+}
+
+// removed calls the callback of an entry that expired or was evicted, if it
+// was stored with SetWithExpireCallback.
+func (c *Cache) removed(value any) {
+	entry, ok := value.(*callbackValue)
+	if !ok || entry.onRemove == nil {
+		return
+	}
+
+	c.callback(func() { entry.onRemove(c.decompress(entry.value)) })
+}
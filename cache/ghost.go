@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultGhostSize is the number of evicted keys remembered for the eviction
+// regret when neither Config.EvictionGhostSize nor MaxSize is set.
+const defaultGhostSize = 1024
+
+// ghostList remembers the most recently evicted keys, without their values,
+// so that a later miss on one of them can be counted as eviction regret.
+//
+// It has its own lock since misses are recorded outside the cache lock.
+type ghostList struct {
+	lock  sync.Mutex
+	size  int
+	keys  map[string]*list.Element
+	order *list.List
+
+	// len mirrors order.Len(), so writes can skip the lock while the list is
+	// empty, which it stays unless metrics are enabled.
+	len atomic.Int64
+}
+
+// newGhostList creates a ghost list holding up to size keys.
+func newGhostList(size int) *ghostList {
+	return &ghostList{
+		size:  size,
+		keys:  make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// add remembers an evicted key, forgetting the oldest one if the list is full.
+func (g *ghostList) add(key string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if elem, ok := g.keys[key]; ok {
+		g.order.MoveToBack(elem)
+		return
+	}
+
+	if g.order.Len() >= g.size {
+		oldest := g.order.Front()
+		g.order.Remove(oldest)
+		delete(g.keys, oldest.Value.(string))
+	}
+
+	g.keys[key] = g.order.PushBack(key)
+	g.len.Store(int64(g.order.Len()))
+}
+
+// remove forgets key and reports whether it was remembered.
+func (g *ghostList) remove(key string) bool {
+	if g.len.Load() == 0 {
+		return false
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	elem, ok := g.keys[key]
+	if !ok {
+		return false
+	}
+
+	g.order.Remove(elem)
+	delete(g.keys, key)
+	g.len.Store(int64(g.order.Len()))
+	return true
+}
+
+// ghostSize returns the capacity of the ghost list.
+func (c *Cache) ghostSize() int {
+	switch {
+	case c.config.EvictionGhostSize > 0:
+		return c.config.EvictionGhostSize
+	case c.config.MaxSize > 0:
+		return c.config.MaxSize
+	default:
+		return defaultGhostSize
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+	"unique"
+)
+
+// CompositeKey builds a single cache key from several parts.
+//
+// Each part is prefixed with its length, so different part lists never produce
+// the same key, even when the parts contain separator characters. For example,
+// ("1", "23") and ("12", "3") encode to "1:1|2:23" and "2:12|1:3". Use it to key
+// Set/Get on identifiers made of multiple fields:
+//
+//	c.Set(cache.CompositeKey("user", userID, "profile"), profile)
+func CompositeKey(parts ...string) string {
+	var b strings.Builder
+
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(strconv.Itoa(len(part)))
+		b.WriteByte(':')
+		b.WriteString(part)
+	}
+
+	return b.String()
+}
+
+// transformKey returns the key under which key is stored, after applying
+// Config.KeyTransform. Every entry point taking a key or a key prefix calls
+// it once, before anything else, so the engines, the side tables, the size
+// limits, the events and the shadow only ever see keys transformed once.
+func (c *Cache) transformKey(key string) string {
+	if c.config.KeyTransform == nil {
+		return key
+	}
+
+	return c.config.KeyTransform(key)
+}
+
+// internKey returns the canonical copy of a key about to be stored when
+// Config.InternKeys is set, and the key itself otherwise. The handle of the
+// copy is kept until the entry is forgotten: the pool drops the copies no
+// handle refers to, after which identical keys would no longer share it.
+func (c *Cache) internKey(key string) string {
+	if !c.config.InternKeys {
+		return key
+	}
+
+	handle := unique.Make(key)
+
+	c.internLock.Lock()
+	defer c.internLock.Unlock()
+
+	if c.interned == nil {
+		c.interned = make(map[string]unique.Handle[string])
+	}
+	c.interned[handle.Value()] = handle
+
+	return handle.Value()
+}
+
+// forgetInterned drops the intern pool handle of a removed entry.
+func (c *Cache) forgetInterned(key string) {
+	if !c.config.InternKeys {
+		return
+	}
+
+	c.internLock.Lock()
+	defer c.internLock.Unlock()
+
+	delete(c.interned, key)
+}
+
+// resetInterned drops the intern pool handles of every entry.
+func (c *Cache) resetInterned() {
+	c.internLock.Lock()
+	defer c.internLock.Unlock()
+
+	c.interned = nil
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// latencySubBits is the number of bits used for the linear sub-buckets of
+	// each power of two, giving a relative precision of 1/2^latencySubBits.
+	latencySubBits = 3
+
+	latencySubBuckets = 1 << latencySubBits
+
+	// latencyLinear is the number of nanosecond values recorded exactly.
+	latencyLinear = 2 * latencySubBuckets
+
+	latencyBuckets = latencyLinear + (64-latencySubBits-1)*latencySubBuckets
+)
+
+// LatencyPercentiles summarizes a latency distribution.
+type LatencyPercentiles struct {
+	Count int64
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+}
+
+// latencyHistogram is a lock-free, HDR-style histogram of durations.
+//
+// Values are grouped in log-linear buckets: each power of two is split into
+// latencySubBuckets linear sub-buckets, so recorded values are reported with
+// a relative error below 1/latencySubBuckets regardless of their magnitude.
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Int64
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.counts[latencyBucket(uint64(d))].Add(1)
+}
+
+func (h *latencyHistogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
+
+func (h *latencyHistogram) percentiles() LatencyPercentiles {
+	var counts [latencyBuckets]int64
+	var total int64
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+		total += counts[i]
+	}
+
+	return LatencyPercentiles{
+		Count: total,
+		P50:   percentile(&counts, total, 0.50),
+		P90:   percentile(&counts, total, 0.90),
+		P99:   percentile(&counts, total, 0.99),
+		P999:  percentile(&counts, total, 0.999),
+	}
+}
+
+// percentile returns the lower bound of the bucket holding the q-th quantile.
+func percentile(counts *[latencyBuckets]int64, total int64, q float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(q*float64(total) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, count := range counts {
+		seen += count
+		if seen >= rank {
+			return time.Duration(latencyBucketValue(i))
+		}
+	}
+
+	return 0
+}
+
+// latencyBucket returns the bucket index of a value in nanoseconds.
+func latencyBucket(v uint64) int {
+	if v < latencyLinear {
+		return int(v)
+	}
+
+	exp := bits.Len64(v) - 1
+	sub := int(v>>(exp-latencySubBits)) & (latencySubBuckets - 1)
+
+	return latencyLinear + (exp-latencySubBits-1)*latencySubBuckets + sub
+}
+
+// latencyBucketValue returns the lowest value in nanoseconds of a bucket.
+func latencyBucketValue(i int) uint64 {
+	if i < latencyLinear {
+		return uint64(i)
+	}
+
+	i -= latencyLinear
+	exp := i/latencySubBuckets + latencySubBits + 1
+	sub := uint64(i % latencySubBuckets)
+
+	return (latencySubBuckets + sub) << (exp - latencySubBits)
+}
@@ -0,0 +1,128 @@
+package cache
+
+import "fmt"
+
+// loadCall is a GetOrSet loader in progress, shared by the callers waiting
+// for the same key.
+type loadCall struct {
+	done    chan struct{}
+	value   any
+	err     error
+	waiters int
+}
+
+// GetOrSet returns the value of key, calling load and storing its result if
+// the key is missing or expired.
+//
+// Concurrent callers for the same key share a single call to load: the first
+// one runs it while the others wait for its result. With Config.MaxLoadWaiters,
+// callers beyond the limit return ErrTooManyWaiters right away. Errors of load
+// are returned to every waiting caller and nothing is stored. If load panics,
+// the panic propagates to the caller that ran it, and the waiting callers get
+// ErrLoadPanicked.
+func (c *Cache) GetOrSet(key string, load func() (any, error)) (any, error) {
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+
+	flight := c.transformKey(key)
+
+	c.loadLock.Lock()
+	if call, ok := c.loads[flight]; ok {
+		if c.config.MaxLoadWaiters > 0 && call.waiters >= c.config.MaxLoadWaiters {
+			c.loadLock.Unlock()
+			return nil, ErrTooManyWaiters
+		}
+		call.waiters++
+		c.loadLock.Unlock()
+
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := c.startLoad(flight)
+	c.loadLock.Unlock()
+
+	if recovered := c.runLoad(key, flight, call, load); recovered != nil {
+		panic(recovered)
+	}
+
+	return call.value, call.err
+}
+
+// GetOrRefresh returns the value of key like GetOrSet, but serves a stale
+// value (see GetStale and Config.StaleGrace) right away while load refreshes
+// it in the background.
+//
+// Refreshes are coalesced per key: however many callers read the stale value
+// at once, a single call to load runs, and a GetOrSet of the key meanwhile
+// waits for it instead of loading again. An error of a background refresh is
+// dropped and the stale value is served until the next refresh or the end of
+// the grace period. A panic of a background refresh is reported to
+// Config.OnCallbackPanic. Missing keys are loaded synchronously, as by GetOrSet.
+func (c *Cache) GetOrRefresh(key string, load func() (any, error)) (any, error) {
+	value, stale, ok := c.GetStale(key)
+	if !ok {
+		return c.GetOrSet(key, load)
+	}
+
+	if stale {
+		flight := c.transformKey(key)
+
+		c.loadLock.Lock()
+		if _, loading := c.loads[flight]; !loading {
+			call := c.startLoad(flight)
+			go c.loadInBackground(key, flight, call, load)
+		}
+		c.loadLock.Unlock()
+	}
+
+	return value, nil
+}
+
+// startLoad registers a load of the transformed key flight. The caller must
+// hold loadLock and have checked that no load of the key is in progress.
+func (c *Cache) startLoad(flight string) *loadCall {
+	call := &loadCall{done: make(chan struct{})}
+	if c.loads == nil {
+		c.loads = make(map[string]*loadCall)
+	}
+	c.loads[flight] = call
+
+	return call
+}
+
+// runLoad calls load for a call registered by startLoad, stores its result on
+// success and releases the callers waiting for it. If load panics, the
+// waiting callers get ErrLoadPanicked and the recovered value is returned,
+// for the caller that owns the load to panic with it again.
+func (c *Cache) runLoad(key, flight string, call *loadCall, load func() (any, error)) (recovered any) {
+	defer func() {
+		if recovered = recover(); recovered != nil {
+			call.value, call.err = nil, fmt.Errorf("%w: %v", ErrLoadPanicked, recovered)
+		}
+
+		c.loadLock.Lock()
+		delete(c.loads, flight)
+		c.loadLock.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = load()
+	if call.err == nil {
+		c.Set(key, call.value)
+	}
+
+	return nil
+}
+
+// loadInBackground runs a load registered by startLoad from a background
+// goroutine, which owns the load. A panic of load is handled like the panics of
+// the other callbacks (see Config.OnCallbackPanic).
+func (c *Cache) loadInBackground(key, flight string, call *loadCall, load func() (any, error)) {
+	c.callback(func() {
+		if recovered := c.runLoad(key, flight, call, load); recovered != nil {
+			panic(recovered)
+		}
+	})
+}
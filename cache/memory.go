@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// MemoryEviction selects the items evicted when the memory usage exceeds the
+// memory limits.
+type MemoryEviction int
+
+const (
+	// MemoryEvictByPolicy evicts items in the order of the eviction policy,
+	// like the evictions of a full cache.
+	MemoryEvictByPolicy MemoryEviction = iota
+
+	// MemoryEvictLargest evicts the largest items first, as measured by Sizer,
+	// or by their compressed size with Compress, to release the most memory
+	// with the fewest evictions. Finding them sizes every item on each
+	// eviction batch.
+	MemoryEvictLargest
+)
+
+const (
+	// memoryEvictBatchFactor multiplies the eviction batch size when the
+	// memory-pressure check triggers, so memory is released faster than
+	// through regular evictions.
+	memoryEvictBatchFactor = 4
+
+	// maxMemoryEvictRounds bounds the number of eviction batches in a single
+	// memory check, so a limit that can't be reached by evicting cache items
+	// doesn't hold the lock forever.
+	maxMemoryEvictRounds = 1024
+)
+
+// startCheckMemoryUsage periodically monitors the cache's memory usage.
+//
+// It is only started by New if memory limits are set in CacheConfig, and runs
+// at the configured interval (`MemoryCheckInterval`). When memory usage
+// exceeds `MemoryLimits` (or `MemoryHighWatermark`), the cache triggers
+// cleanup to free up space
+func (c *Cache) startCheckMemoryUsage() {
+	ticker := time.NewTicker(c.config.MemoryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.checkMemoryUsage()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// checkMemoryUsage starts evicting once the memory usage exceeds the high
+// watermark, and evicts items in batches until it drops to the low watermark
+// or the cache is empty.
+//
+// The memory usage is read again after every batch, without holding the
+// lock, which is only taken to evict each batch: reading the process heap
+// stops the world, and other calls go on between the batches. At most
+// maxMemoryEvictRounds batches are evicted per check; if the low watermark
+// is not reached by then, the next check goes on evicting even if the usage
+// is already below the high watermark.
+func (c *Cache) checkMemoryUsage() {
+	usage := c.memoryUsage()
+	if !c.memoryPressure && usage <= c.config.memoryHighWatermark() {
+		return
+	}
+	c.memoryPressure = true
+
+	batch := c.evictBatchSize() * memoryEvictBatchFactor
+	low := c.config.memoryLowWatermark()
+
+	for round := 0; round < maxMemoryEvictRounds; round++ {
+		if usage <= low || !c.evictMemoryBatch(batch) {
+			c.memoryPressure = false
+			return
+		}
+
+		usage = c.memoryUsage()
+	}
+}
+
+// evictMemoryBatch evicts up to n items under the write lock, and reports
+// whether the cache held any.
+func (c *Cache) evictMemoryBatch(n int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.engine.Len() == 0 {
+		return false
+	}
+
+	c.evictForMemory(n)
+	return true
+}
+
+// evictForMemory removes up to n items chosen by Config.MemoryEviction.
+// The caller must hold the write lock.
+func (c *Cache) evictForMemory(n int) {
+	if c.config.MemoryEviction == MemoryEvictLargest {
+		c.evictLargest(n)
+		return
+	}
+
+	c.evict(n)
+}
+
+// evictLargest removes the n largest items that are not pinned, as measured
+// by Sizer, starting with the ones Config.CanEvict allows. The caller must
+// hold the write lock.
+func (c *Cache) evictLargest(n int) {
+	type candidate struct {
+		key    string
+		size   int
+		vetoed bool
+	}
+
+	var candidates []candidate
+	c.engine.Range(func(key string, value any) bool {
+		if _, pinned := c.pinned[key]; !pinned {
+			candidates = append(candidates, candidate{
+				key:    key,
+				size:   c.storedSize(value),
+				vetoed: !c.canEvict(key, value),
+			})
+		}
+		return true
+	})
+
+	// Entries vetoed by CanEvict only go once the others are gone
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.vetoed != b.vetoed {
+			if a.vetoed {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(b.size, a.size)
+	})
+
+	for _, victim := range candidates[:min(n, len(candidates))] {
+		value, _, found := c.engine.Peek(victim.key)
+		if !found {
+			continue
+		}
+
+		c.engine.Delete(victim.key)
+		c.recordEviction(victim.key, value)
+	}
+}
+
+// memoryUsage returns the current memory usage in bytes, from MemoryUsage,
+// the size estimate or the process heap.
+func (c *Cache) memoryUsage() uint64 {
+	if c.config.MemoryUsage != nil {
+		var usage uint64
+		c.callback(func() { usage = c.config.MemoryUsage() })
+		return usage
+	}
+
+	if c.config.EstimateSize {
+		return c.EstimatedBytes()
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return mem.Alloc
+}
@@ -1,23 +1,62 @@
 package cache
 
-import "sync/atomic"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // Metrics provides tracking for cache performance statistics.
 //
 // This struct collects and stores various cache metrics, including:
 //   - Hits: Number of successful key lookups.
-//   - Misses: Number of failed key lookups (key not found or expired).
+//   - Misses: Number of failed key lookups on keys that are not in the cache.
+//   - Expirations: Number of failed key lookups on keys that are in the cache
+//     but expired at read time.
+//   - Evictions: Number of items removed by the eviction policy.
+//   - EvictionRegret: Number of failed key lookups on keys that were recently
+//     evicted, a sign that the eviction policy or the cache size is a poor fit.
 //
 // Metrics help monitor cache efficiency and can be used for performance tuning.
 type Metrics struct {
-	hits   int64
-	misses int64
+	// The counters are plain atomics, so increments on the Get and Set paths
+	// share no lock. Snapshot rereads them until two reads agree instead.
+	hits        int64
+	misses      int64
+	expirations int64
+	evictions   int64
+	regret      int64
+
+	// lastEviction is the time of the last eviction in Unix nanoseconds, or 0.
+	lastEviction atomic.Int64
+	evictionRate evictionWindow
+
+	getLatency latencyHistogram
+	setLatency latencyHistogram
+
+	// clock returns the current time for EvictionsPerMinute. The cache sets it
+	// to its own clock; it defaults to time.Now.
+	clock func() time.Time
+}
+
+// MetricsSnapshot is a copy of the cache metrics.
+//
+// The rates always match the counts, but the counts may be torn by concurrent
+// updates (see Metrics.Snapshot).
+type MetricsSnapshot struct {
+	Hits           int64
+	Misses         int64
+	Expirations    int64
+	Evictions      int64
+	EvictionRegret int64
+	HitRate        float64
+	MissRate       float64
 }
 
 func NewMetrics() *Metrics {
 	return &Metrics{
-		hits:   0,
-		misses: 0,
+		hits:      0,
+		misses:    0,
+		evictions: 0,
 	}
 }
 
@@ -29,6 +68,26 @@ func (m *Metrics) IncrementMisses() {
 	atomic.AddInt64(&m.misses, 1)
 }
 
+func (m *Metrics) IncrementExpirations() {
+	atomic.AddInt64(&m.expirations, 1)
+}
+
+func (m *Metrics) IncrementEvictions() {
+	atomic.AddInt64(&m.evictions, 1)
+}
+
+// RecordEviction counts an eviction that happened at the given time, updating
+// Evictions, LastEviction and EvictionsPerMinute.
+func (m *Metrics) RecordEviction(at time.Time) {
+	m.IncrementEvictions()
+	m.lastEviction.Store(at.UnixNano())
+	m.evictionRate.record(at)
+}
+
+func (m *Metrics) IncrementEvictionRegret() {
+	atomic.AddInt64(&m.regret, 1)
+}
+
 func (m *Metrics) Hits() int64 {
 	return atomic.LoadInt64(&m.hits)
 }
@@ -37,21 +96,154 @@ func (m *Metrics) Misses() int64 {
 	return atomic.LoadInt64(&m.misses)
 }
 
-func (m *Metrics) HitRate() float64 {
-	hits := m.Hits()
-	misses := m.Misses()
+// Expirations returns the number of reads of keys that were in the cache but
+// had expired. They are not counted as misses, but count as failed lookups in
+// HitRate and MissRate.
+func (m *Metrics) Expirations() int64 {
+	return atomic.LoadInt64(&m.expirations)
+}
 
-	if hits == 0 && misses == 0 {
-		return 0
+func (m *Metrics) Evictions() int64 {
+	return atomic.LoadInt64(&m.evictions)
+}
+
+// LastEviction returns the time of the last eviction recorded with
+// RecordEviction, or the zero time if there was none since the last Reset.
+func (m *Metrics) LastEviction() time.Time {
+	nanos := m.lastEviction.Load()
+	if nanos == 0 {
+		return time.Time{}
 	}
 
-	return float64(hits) / float64(hits+misses)
+	return time.Unix(0, nanos)
+}
+
+// EvictionsPerMinute returns the number of evictions recorded with
+// RecordEviction during the last minute. It is counted in one-second steps, so
+// evictions leave the count up to a second earlier than a full minute.
+func (m *Metrics) EvictionsPerMinute() int64 {
+	now := time.Now
+	if m.clock != nil {
+		now = m.clock
+	}
+
+	return m.evictionRate.count(now())
+}
+
+// EvictionRegret returns the number of reads that missed a key evicted
+// shortly before, among the last evicted keys remembered by the cache (see
+// Config.EvictionGhostSize). A high regret relative to Evictions means the
+// policy keeps evicting keys that are still in use.
+func (m *Metrics) EvictionRegret() int64 {
+	return atomic.LoadInt64(&m.regret)
+}
+
+func (m *Metrics) HitRate() float64 {
+	return hitRate(m.Hits(), m.Misses()+m.Expirations())
 }
 
 func (m *Metrics) MissRate() float64 {
-	return 1 - m.HitRate()
+	return missRate(m.Hits(), m.Misses()+m.Expirations())
 }
 
 func (m *Metrics) GetMetrics() *Metrics {
 	return m
 }
+
+// RecordGetLatency adds the duration of a Get call to the latency histogram.
+func (m *Metrics) RecordGetLatency(d time.Duration) {
+	m.getLatency.record(d)
+}
+
+// RecordSetLatency adds the duration of a Set call to the latency histogram.
+func (m *Metrics) RecordSetLatency(d time.Duration) {
+	m.setLatency.record(d)
+}
+
+// GetLatency returns the latency percentiles of Get calls.
+//
+// Latencies are only recorded when `Config.LatencyMetrics` is enabled.
+// Reported values are accurate to within 12.5%.
+func (m *Metrics) GetLatency() LatencyPercentiles {
+	return m.getLatency.percentiles()
+}
+
+// SetLatency returns the latency percentiles of Set calls.
+//
+// Latencies are only recorded when `Config.LatencyMetrics` is enabled.
+// Reported values are accurate to within 12.5%.
+func (m *Metrics) SetLatency() LatencyPercentiles {
+	return m.setLatency.percentiles()
+}
+
+// Reset sets all the counters and latency histograms back to zero.
+//
+// It is useful to measure per-interval hit rates in long-running processes.
+// Rates computed right after a reset return 0 until new hits or misses are recorded.
+// Counters are reset one at a time, so an update concurrent with Reset may be
+// kept in some counters and dropped from others.
+func (m *Metrics) Reset() {
+	atomic.StoreInt64(&m.hits, 0)
+	atomic.StoreInt64(&m.misses, 0)
+	atomic.StoreInt64(&m.expirations, 0)
+	atomic.StoreInt64(&m.evictions, 0)
+	atomic.StoreInt64(&m.regret, 0)
+	m.lastEviction.Store(0)
+	m.evictionRate.reset()
+	m.getLatency.reset()
+	m.setLatency.reset()
+}
+
+// snapshotAttempts bounds how many times Snapshot rereads the counters
+// waiting for a read no update interleaved with.
+const snapshotAttempts = 4
+
+// Snapshot returns a copy of all the counters and their rates.
+//
+// The rates are always computed from the returned counts. The counts are read
+// again until two reads in a row agree, so they are usually captured together,
+// but under a steady stream of updates Snapshot gives up after a few attempts
+// and the counts may be torn: one of them may include an update that another
+// one misses. Increments never wait for Snapshot.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	s := m.readCounters()
+	for i := 1; i < snapshotAttempts; i++ {
+		again := m.readCounters()
+		if again == s {
+			break
+		}
+		s = again
+	}
+
+	s.HitRate = hitRate(s.Hits, s.Misses+s.Expirations)
+	s.MissRate = missRate(s.Hits, s.Misses+s.Expirations)
+
+	return s
+}
+
+// readCounters loads every counter into a snapshot, without the rates.
+func (m *Metrics) readCounters() MetricsSnapshot {
+	return MetricsSnapshot{
+		Hits:           atomic.LoadInt64(&m.hits),
+		Misses:         atomic.LoadInt64(&m.misses),
+		Expirations:    atomic.LoadInt64(&m.expirations),
+		Evictions:      atomic.LoadInt64(&m.evictions),
+		EvictionRegret: atomic.LoadInt64(&m.regret),
+	}
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits == 0 && misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
+
+func missRate(hits, misses int64) float64 {
+	if hits == 0 && misses == 0 {
+		return 0
+	}
+
+	return float64(misses) / float64(hits+misses)
+}
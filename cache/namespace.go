@@ -0,0 +1,83 @@
+package cache
+
+import "strings"
+
+// NamespacedCache is a view of a Cache where every key is transparently
+// prefixed with a namespace, so several modules can share one cache without
+// their keys colliding. It is returned by Cache.Namespace.
+//
+// Entries of all namespaces share the capacity and the eviction policy of the
+// underlying cache.
+type NamespacedCache struct {
+	cache  *Cache
+	prefix string
+}
+
+// Namespace returns a view of the cache whose keys are scoped by prefix.
+//
+// The prefix is encoded like a CompositeKey part, so no namespace overlaps
+// another one: keys of "user" never appear in "users".
+func (c *Cache) Namespace(prefix string) *NamespacedCache {
+	return &NamespacedCache{
+		cache:  c,
+		prefix: CompositeKey(prefix) + "|",
+	}
+}
+
+// DeletePrefix removes every entry whose key starts with prefix and returns the
+// number of entries removed. The prefix goes through Config.KeyTransform like
+// a key, which suits transforms that keep prefixes, such as strings.ToLower.
+func (c *Cache) DeletePrefix(prefix string) int {
+	prefix = c.transformKey(prefix)
+
+	return c.DeleteFunc(func(key string, _ any) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// Get retrieves the value of key in the namespace (see Cache.Get).
+func (n *NamespacedCache) Get(key string) (any, bool) {
+	return n.cache.Get(n.prefix + key)
+}
+
+// Set stores a key-value pair in the namespace (see Cache.Set).
+func (n *NamespacedCache) Set(key string, value any) {
+	n.cache.Set(n.prefix+key, value)
+}
+
+// Delete removes key from the namespace.
+func (n *NamespacedCache) Delete(key string) {
+	n.cache.Delete(n.prefix + key)
+}
+
+// Has checks whether key exists in the namespace.
+func (n *NamespacedCache) Has(key string) bool {
+	return n.cache.Has(n.prefix + key)
+}
+
+// Keys returns the keys of the namespace, without the namespace prefix, in
+// eviction order. With Config.KeyTransform, the keys are reported transformed.
+func (n *NamespacedCache) Keys() []string {
+	prefix := n.cache.transformKey(n.prefix)
+
+	var keys []string
+	for _, key := range n.cache.Keys() {
+		if stripped, ok := strings.CutPrefix(key, prefix); ok {
+			keys = append(keys, stripped)
+		}
+	}
+
+	return keys
+}
+
+// DeletePrefix removes every entry of the namespace whose key starts with
+// prefix and returns the number of entries removed.
+func (n *NamespacedCache) DeletePrefix(prefix string) int {
+	return n.cache.DeletePrefix(n.prefix + prefix)
+}
+
+// Clear removes all the entries of the namespace, leaving other namespaces
+// and non-namespaced keys intact.
+func (n *NamespacedCache) Clear() {
+	n.cache.DeletePrefix(n.prefix)
+}
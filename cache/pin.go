@@ -0,0 +1,120 @@
+package cache
+
+import "github.com/hugocarreira/easycache/engine"
+
+// Pin exempts a key from eviction, e.g. for configuration or feature flags
+// that must stay cached under capacity or memory pressure. The eviction
+// policy then picks its victims among the other keys, in its usual order.
+//
+// The pin applies to the key, present or not, until Unpin: the entry can
+// still expire, be deleted or be replaced, and a new entry stored under the
+// key is pinned too. If every key is pinned, nothing is evicted and the cache
+// may grow past MaxSize.
+func (c *Cache) Pin(key string) {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pinned == nil {
+		c.pinned = make(map[string]struct{})
+	}
+	c.pinned[key] = struct{}{}
+}
+
+// Unpin makes a key pinned with Pin evictable again.
+func (c *Cache) Unpin(key string) {
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.pinned, key)
+}
+
+// IsPinned reports whether a key is pinned.
+func (c *Cache) IsPinned(key string) bool {
+	key = c.transformKey(key)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, pinned := c.pinned[key]
+	return pinned
+}
+
+// evictUnpinned removes the item the engine would evict next, skipping the
+// pinned keys and the ones vetoed by Config.CanEvict, and returns it. The
+// caller must hold the write lock.
+//
+// Without pinned keys nor CanEvict it is the engine's Evict. Otherwise the
+// candidates are offered in eviction order by engines implementing
+// engine.SelectiveEvicter, which all the built-in policies but Tiered do. For
+// the other engines they are taken from Keys, which lists every key and
+// costs O(n) per eviction. If CanEvict vetoes every unpinned candidate, the
+// first one is evicted.
+func (c *Cache) evictUnpinned() (string, any, bool) {
+	if len(c.pinned) == 0 && c.config.CanEvict == nil {
+		return c.engine.Evict()
+	}
+
+	if evicter, ok := c.engine.(engine.SelectiveEvicter); ok {
+		return c.evictSelectively(evicter)
+	}
+
+	var (
+		fallback    string
+		hasFallback bool
+	)
+	for _, key := range c.engine.Keys() {
+		if _, pinned := c.pinned[key]; pinned {
+			continue
+		}
+		if !hasFallback {
+			fallback, hasFallback = key, true
+		}
+
+		value, _, found := c.engine.Peek(key)
+		if found && c.canEvict(key, value) {
+			c.engine.Delete(key)
+			return key, value, true
+		}
+	}
+
+	if !hasFallback {
+		return "", nil, false
+	}
+
+	value, _, _ := c.engine.Peek(fallback)
+	c.engine.Delete(fallback)
+	return fallback, value, true
+}
+
+// evictSelectively is evictUnpinned for an engine.SelectiveEvicter. The
+// caller must hold the write lock.
+func (c *Cache) evictSelectively(evicter engine.SelectiveEvicter) (string, any, bool) {
+	unpinned := func(key string, _ any) bool {
+		_, pinned := c.pinned[key]
+		return !pinned
+	}
+
+	key, value, evicted := evicter.EvictFunc(func(key string, value any) bool {
+		return unpinned(key, value) && c.canEvict(key, value)
+	})
+	if evicted || c.config.CanEvict == nil {
+		return key, value, evicted
+	}
+
+	return evicter.EvictFunc(unpinned)
+}
+
+// canEvict reports whether Config.CanEvict allows evicting an entry.
+func (c *Cache) canEvict(key string, value any) bool {
+	if c.config.CanEvict == nil {
+		return true
+	}
+
+	allowed := true
+	c.callback(func() { allowed = c.config.CanEvict(key, c.unwrap(value)) })
+	return allowed
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// ParseEvictionPolicy returns the eviction policy matching its name, such as
+// "lru" or "LFU". Names are case-insensitive and match EvictionPolicy.String.
+func ParseEvictionPolicy(s string) (EvictionPolicy, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+
+	for p := Basic; p.String() != "unknown"; p++ {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+
+	return Basic, fmt.Errorf("easycache: unknown eviction policy %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the policy by name.
+func (p EvictionPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so the policy can be read
+// by name from text-based configuration files (JSON, YAML, ...).
+func (p *EvictionPolicy) UnmarshalText(text []byte) error {
+	policy, err := ParseEvictionPolicy(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = policy
+	return nil
+}
+
+// SwitchPolicy replaces the engine of a running cache with one using the
+// given eviction policy, keeping its entries.
+//
+// The live entries are moved to the new engine with their values and
+// expiration times, from the next to be evicted to the last, so the recency
+// order carries over to the new policy. Access frequencies and weights are not
+// carried over. The switch happens under the cache lock: concurrent calls wait
+// until it completes and never see a partially filled engine.
+//
+// It returns an error wrapping ErrInvalidConfig for an unknown policy or
+// Custom, whose engine only Config.CustomEngine can create, and ErrClosed if
+// the cache is closed.
+func (c *Cache) SwitchPolicy(policy EvictionPolicy) error {
+	if policy.String() == "unknown" {
+		return fmt.Errorf("%w: unknown eviction policy %d", ErrInvalidConfig, policy)
+	}
+	if policy == Custom {
+		return fmt.Errorf("%w: can't switch to the custom eviction policy", ErrInvalidConfig)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	old := c.engine
+	next := c.newEngine(policy)
+	if reserver, ok := next.(engine.Reserver); ok {
+		reserver.Reserve(old.Len())
+	}
+
+	for _, key := range old.Keys() {
+		value, expiresAt, exists := old.Peek(key)
+		if !exists || (old.IsExpirable() && old.IsExpired(key)) {
+			c.forget(key)
+			continue
+		}
+
+		if next.IsExpirable() {
+			next.SetWithTTL(key, value, expiresAt)
+		} else {
+			next.Set(key, value)
+		}
+	}
+
+	c.engine = next
+	c.policy = policy
+
+	if closer, ok := old.(engine.Closer); ok {
+		closer.Close()
+	}
+
+	return nil
+}
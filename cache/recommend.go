@@ -0,0 +1,60 @@
+package cache
+
+// WorkloadProfile describes the access pattern a cache is expected to serve.
+// It is used by Recommend to suggest an eviction policy.
+type WorkloadProfile struct {
+	// ReadRatio is the fraction of operations that are reads, from 0 to 1.
+	ReadRatio float64
+
+	// Scans reports whether the workload includes sequential scans that touch
+	// many keys only once (batch jobs, full-table reads, crawlers, ...).
+	Scans bool
+
+	// KeyCardinality is the expected number of distinct keys. 0 means unknown.
+	KeyCardinality int
+
+	// MaxSize is the planned cache capacity. 0 means unknown.
+	MaxSize int
+
+	// Expiring reports whether entries must expire after a fixed time. Only
+	// the Basic and FIFO policies support expiration.
+	Expiring bool
+}
+
+// readHeavyRatio is the read ratio from which frequency tracking pays off.
+const readHeavyRatio = 0.9
+
+// writeHeavyRatio is the read ratio below which the workload is write-heavy.
+const writeHeavyRatio = 0.5
+
+// Recommend suggests an eviction policy for the given workload. See
+// RecommendWithRationale for the reasoning behind the suggestion.
+func Recommend(workload WorkloadProfile) EvictionPolicy {
+	policy, _ := RecommendWithRationale(workload)
+	return policy
+}
+
+// RecommendWithRationale suggests an eviction policy for the given workload
+// and explains why it was chosen.
+//
+// The rules follow the benchmarks in the tests package: FIFO has the cheapest
+// writes, LRU the cheapest reads that still track access, LFU keeps O(1) Get
+// while protecting popular keys, and LRU-K avoids being flushed by scans.
+func RecommendWithRationale(workload WorkloadProfile) (EvictionPolicy, string) {
+	switch {
+	case workload.Expiring && workload.ReadRatio < writeHeavyRatio:
+		return FIFO, "entries must expire and the workload is write-heavy; FIFO supports expiration with the cheapest writes"
+	case workload.Expiring:
+		return Basic, "entries must expire after a fixed time; Basic evicts the entries closest to expiring and cleans up expired ones periodically"
+	case workload.Scans:
+		return LRUK, "scans touch keys only once; LRU-K keeps keys with repeated accesses instead of the scanned ones"
+	case workload.MaxSize > 0 && workload.KeyCardinality > 0 && workload.KeyCardinality <= workload.MaxSize:
+		return FIFO, "every key fits in the cache so nothing is evicted; FIFO has the lowest bookkeeping cost"
+	case workload.ReadRatio < writeHeavyRatio:
+		return FIFO, "the workload is write-heavy; FIFO has the cheapest writes and does no work on reads"
+	case workload.ReadRatio >= readHeavyRatio:
+		return LFU, "the workload is read-heavy; LFU keeps the most popular keys with O(1) reads"
+	default:
+		return LRU, "the workload mixes reads and writes; LRU keeps recently used keys at a low cost"
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// computedValue holds a value stored with the time it took to compute, used by
+// the probabilistic early expiration (see Config.EarlyRecompute).
+type computedValue struct {
+	value       any
+	computeTime time.Duration
+}
+
+// SetWithComputeTime stores a value like Set, recording how long it took to
+// compute. With Config.EarlyRecompute, the compute time decides how early
+// before its expiration Get starts reporting the key as missing.
+func (c *Cache) SetWithComputeTime(key string, value any, computeTime time.Duration) {
+	key = c.transformKey(key)
+
+	if !c.accept(key, value) {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.set(key, &computedValue{value: value, computeTime: computeTime})
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+}
+
+// unwrap returns the original value of a stored one, removing the expire
+// callback, the compute time and the compression.
+func (c *Cache) unwrap(value any) any {
+	if entry, ok := value.(*callbackValue); ok {
+		value = entry.value
+	}
+	if computed, ok := value.(*computedValue); ok {
+		value = computed.value
+	}
+
+	return c.decompress(value)
+}
+
+// expiresEarly implements the XFetch algorithm ("Optimal Probabilistic Cache
+// Stampede Prevention", Vattani et al.): a key is reported as expired when
+//
+//	now - computeTime * EarlyRecompute * ln(rand()) >= expiresAt
+//
+// so the closer the key is to its expiration and the longer it takes to
+// compute, the more likely a caller is to recompute it early, while the other
+// callers still get the cached value. The caller must hold the lock.
+func (c *Cache) expiresEarly(key string) bool {
+	if c.config.EarlyRecompute <= 0 {
+		return false
+	}
+
+	value, expiresAt, exists := c.engine.Peek(key)
+	if !exists || expiresAt.IsZero() {
+		return false
+	}
+
+	computed, ok := value.(*computedValue)
+	if !ok || computed.computeTime <= 0 {
+		return false
+	}
+
+	gap := -float64(computed.computeTime) * c.config.EarlyRecompute * math.Log(1-c.randFloat())
+	return !c.now().Add(time.Duration(gap)).Before(expiresAt)
+}
+
+// randFloat returns a random number in [0, 1) from Config.Rand, or from the
+// global source if it is not set.
+func (c *Cache) randFloat() float64 {
+	if c.config.Rand == nil {
+		return rand.Float64()
+	}
+
+	c.randLock.Lock()
+	defer c.randLock.Unlock()
+
+	return c.config.Rand.Float64()
+}
+
+// randTiebreak returns the source of the numbers breaking the ties between
+// items expiring at the same time in the Basic engine: Config.Rand, or nil
+// to break them by insertion order.
+func (c *Cache) randTiebreak() func() uint64 {
+	if c.config.Rand == nil {
+		return nil
+	}
+
+	return func() uint64 {
+		c.randLock.Lock()
+		defer c.randLock.Unlock()
+
+		return c.config.Rand.Uint64()
+	}
+}
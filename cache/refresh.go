@@ -0,0 +1,139 @@
+package cache
+
+import "time"
+
+const (
+	// minRefreshRetry is the shortest wait before retrying a failed refresh.
+	minRefreshRetry = 10 * time.Millisecond
+
+	// defaultRefreshCheck is how often the refresher of a key that never
+	// expires checks that the key is still cached, without a refresh lead.
+	defaultRefreshCheck = time.Second
+)
+
+// RegisterRefresher keeps key loaded by calling load in the background
+// RefreshAheadLead before the key expires and storing its result, so that
+// reads of a predictable hot key never miss. The key is loaded right away if
+// it is missing, and registering it again replaces its loader.
+//
+// Refreshes share a single call to load with concurrent GetOrSet and
+// GetOrRefresh calls for the key. A failed refresh is retried, after at least
+// a quarter of the lead, until it succeeds. A panic of load is reported to
+// Config.OnCallbackPanic and retried like an error. With policies without
+// expiration support, or for a key that never expires, the key is only loaded
+// when it is missing, which is checked every RefreshAheadLead, or every second
+// without a lead. Refreshers run until UnregisterRefresher or Close.
+func (c *Cache) RegisterRefresher(key string, load func() (any, error)) {
+	flight := c.transformKey(key)
+	stop := make(chan struct{})
+
+	c.refreshLock.Lock()
+	defer c.refreshLock.Unlock()
+
+	if c.closed.Load() {
+		return
+	}
+
+	if previous, ok := c.refreshers[flight]; ok {
+		close(previous)
+	}
+	if c.refreshers == nil {
+		c.refreshers = make(map[string]chan struct{})
+	}
+	c.refreshers[flight] = stop
+
+	go c.refresh(key, flight, load, stop)
+}
+
+// UnregisterRefresher stops refreshing key. The key itself stays in the cache
+// until it expires.
+func (c *Cache) UnregisterRefresher(key string) {
+	flight := c.transformKey(key)
+
+	c.refreshLock.Lock()
+	defer c.refreshLock.Unlock()
+
+	if stop, ok := c.refreshers[flight]; ok {
+		close(stop)
+		delete(c.refreshers, flight)
+	}
+}
+
+// refresh reloads key whenever it is about to expire, until stop is closed
+// or the cache is closed.
+func (c *Cache) refresh(key, flight string, load func() (any, error), stop chan struct{}) {
+	delay, due := c.refreshDelay(flight)
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+
+		if !due {
+			delay, due = c.refreshDelay(flight)
+			continue
+		}
+
+		err := c.reload(key, flight, load)
+		delay, due = c.refreshDelay(flight)
+		if err != nil {
+			delay = max(delay, c.refreshLead()/4, minRefreshRetry)
+		}
+	}
+}
+
+// refreshDelay returns how long to wait before reloading the transformed key
+// flight: none if it is missing, and until RefreshAheadLead before its
+// expiration otherwise. Since an entry may live shorter than the lead, the
+// wait is at least half the remaining lifetime. If the key never expires, it
+// returns false with the delay before checking the key again.
+func (c *Cache) refreshDelay(flight string) (time.Duration, bool) {
+	c.lock.RLock()
+	_, expiresAt, found := c.engine.Peek(flight)
+	expirable := c.engine.IsExpirable()
+	c.lock.RUnlock()
+
+	if !found {
+		return 0, true
+	}
+	if !expirable || expiresAt.IsZero() {
+		if lead := c.refreshLead(); lead > 0 {
+			return lead, false
+		}
+		return defaultRefreshCheck, false
+	}
+
+	remaining := expiresAt.Sub(c.now())
+	return max(remaining-c.refreshLead(), remaining/2, 0), true
+}
+
+// refreshLead returns Config.RefreshAheadLead, or a tenth of TTL if unset.
+func (c *Cache) refreshLead() time.Duration {
+	if c.config.RefreshAheadLead > 0 {
+		return c.config.RefreshAheadLead
+	}
+
+	return c.config.TTL / 10
+}
+
+// reload calls load for key and stores its result, or waits for the load of
+// the key already in progress. A panic of load is handled like the panics of
+// the other callbacks and reported as ErrLoadPanicked.
+func (c *Cache) reload(key, flight string, load func() (any, error)) error {
+	c.loadLock.Lock()
+	if call, ok := c.loads[flight]; ok {
+		c.loadLock.Unlock()
+
+		<-call.done
+		return call.err
+	}
+
+	call := c.startLoad(flight)
+	c.loadLock.Unlock()
+
+	c.loadInBackground(key, flight, call, load)
+	return call.err
+}
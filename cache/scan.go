@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// defaultScanLimit is the page size used by Scan when limit is not positive.
+const defaultScanLimit = 10
+
+// Cursor is the position of a paginated iteration started by Cache.Scan.
+//
+// Keys are visited in lexicographic order, so a key present for the whole
+// iteration is returned exactly once, while keys added or removed in between
+// may or may not be returned.
+type Cursor struct {
+	cache *Cache
+	after string
+	done  bool
+}
+
+// Scan returns the first page of at most limit keys and a cursor to fetch the
+// next pages with Cursor.Next. A limit of 0 or less uses a default of 10.
+//
+// Unlike Keys, Scan never copies every key: each page walks the cache under
+// the read lock but only keeps up to limit keys, so it suits very large caches.
+func (c *Cache) Scan(limit int) ([]string, Cursor) {
+	return c.scan("", false, limit)
+}
+
+// Next returns the next page of at most limit keys and the cursor to continue
+// from. It returns no keys once the iteration is done.
+func (cur Cursor) Next(limit int) ([]string, Cursor) {
+	if cur.done || cur.cache == nil {
+		return nil, cur
+	}
+
+	return cur.cache.scan(cur.after, true, limit)
+}
+
+// Done reports whether every page has been returned.
+func (cur Cursor) Done() bool {
+	return cur.done
+}
+
+// scan returns up to limit keys sorted after the given key, or from the first
+// key if resume is false.
+func (c *Cache) scan(after string, resume bool, limit int) ([]string, Cursor) {
+	if limit <= 0 {
+		limit = defaultScanLimit
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	// page keeps the smallest keys seen so far, largest at the root.
+	page := make(keyHeap, 0, limit)
+	remaining := 0
+	c.engine.Range(func(key string, _ any) bool {
+		if resume && key <= after {
+			return true
+		}
+
+		remaining++
+		switch {
+		case len(page) < limit:
+			heap.Push(&page, key)
+		case key < page[0]:
+			page[0] = key
+			heap.Fix(&page, 0)
+		}
+		return true
+	})
+
+	keys := []string(page)
+	sort.Strings(keys)
+
+	cursor := Cursor{cache: c, done: remaining <= limit}
+	if len(keys) > 0 {
+		cursor.after = keys[len(keys)-1]
+	}
+
+	return keys, cursor
+}
+
+// keyHeap is a max-heap of keys.
+type keyHeap []string
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *keyHeap) Push(x any) {
+	*h = append(*h, x.(string))
+}
+
+func (h *keyHeap) Pop() any {
+	old := *h
+	n := len(old)
+	key := old[n-1]
+	*h = old[:n-1]
+	return key
+}
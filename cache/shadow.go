@@ -0,0 +1,36 @@
+package cache
+
+// AttachShadow runs a shadow cache built from cfg alongside this one, to
+// compare the hit rate of another configuration (typically another eviction
+// policy) on real traffic without changing the results of this cache.
+//
+// Get, Set and Delete calls are mirrored into the shadow, and its statistics
+// are reported by ShadowMetrics. Metrics are always enabled on the shadow.
+// Keys reach the shadow already transformed by Config.KeyTransform, so the
+// KeyTransform of cfg is ignored. Attaching a shadow replaces and closes any
+// previous one.
+func (c *Cache) AttachShadow(cfg Config) {
+	cfg.Metrics = true
+	cfg.KeyTransform = nil
+
+	if previous := c.shadow.Swap(New(&cfg)); previous != nil {
+		previous.Close()
+	}
+}
+
+// DetachShadow stops mirroring calls into the shadow cache and closes it.
+func (c *Cache) DetachShadow() {
+	if previous := c.shadow.Swap(nil); previous != nil {
+		previous.Close()
+	}
+}
+
+// ShadowMetrics returns the metrics of the shadow cache, or nil if no shadow
+// is attached.
+func (c *Cache) ShadowMetrics() *Metrics {
+	if shadow := c.shadow.Load(); shadow != nil {
+		return shadow.Metrics()
+	}
+
+	return nil
+}
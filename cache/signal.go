@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"os"
+	"os/signal"
+)
+
+// WatchSignal clears the cache every time the process receives sig, e.g.
+// SIGHUP to force a cold reload of the backing data. The handler is removed
+// by Close.
+//
+// Signal handling is opt-in: the cache never installs a handler by itself.
+// Other handlers for sig installed with signal.Notify keep receiving it.
+func (c *Cache) WatchSignal(sig os.Signal) {
+	if c.closed.Load() {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-signals:
+				c.Clear()
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
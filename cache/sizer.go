@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"math"
+	"reflect"
+)
+
+// Sizer returns the size in bytes of a cached value.
+type Sizer func(value any) int
+
+// DefaultSizer returns the length of strings and byte slices. For other values
+// it returns the shallow size of the value's type, without following pointers,
+// so a custom Sizer should be set when caching large structures.
+func DefaultSizer(value any) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		return int(reflect.TypeOf(v).Size())
+	}
+}
+
+// unknownSize is passed as the size of a value to have it measured by Sizer.
+// Values of a known size, such as the byte slices of SetBytes, skip the Sizer.
+const unknownSize = -1
+
+// accept reports whether the key and value are within the MaxKeyBytes and
+// MaxValueBytes limits. Rejected entries are counted in RejectedSets.
+func (c *Cache) accept(key string, value any) bool {
+	return c.acceptSized(key, value, unknownSize)
+}
+
+// acceptSized is accept for a value of the given size, or of unknownSize.
+func (c *Cache) acceptSized(key string, value any, size int) bool {
+	if c.config.MaxKeyBytes > 0 && len(key) > c.config.MaxKeyBytes {
+		c.rejectedSets.Add(1)
+		return false
+	}
+
+	if c.config.MaxValueBytes > 0 {
+		if size == unknownSize {
+			size = c.size(value)
+		}
+		if size > c.config.MaxValueBytes {
+			c.rejectedSets.Add(1)
+			return false
+		}
+	}
+
+	return true
+}
+
+// size returns the size in bytes of a value using the configured Sizer.
+func (c *Cache) size(value any) int {
+	if c.config.Sizer != nil {
+		return c.config.Sizer(value)
+	}
+
+	return DefaultSizer(value)
+}
+
+// sizeBuckets are the buckets of SizeHistogram: each one counts the values
+// smaller than its limit and not counted by the previous ones.
+var sizeBuckets = []struct {
+	label string
+	limit int
+}{
+	{"<1KB", 1 << 10},
+	{"1KB-10KB", 10 << 10},
+	{"10KB-100KB", 100 << 10},
+	{"100KB-1MB", 1 << 20},
+	{"1MB-10MB", 10 << 20},
+	{">=10MB", math.MaxInt},
+}
+
+// SizeHistogram returns the number of live values in each size bucket, to
+// help choose MaxValueBytes or the memory limits. Values are measured by
+// Sizer, before compression, and the buckets are "<1KB", "1KB-10KB",
+// "10KB-100KB", "100KB-1MB", "1MB-10MB" and ">=10MB", with 1KB = 1024 bytes.
+// Every bucket is present in the result, empty ones with a count of 0.
+//
+// It sizes every value under the read lock, so it is meant for occasional
+// inspection rather than the hot path.
+func (c *Cache) SizeHistogram() map[string]int {
+	histogram := make(map[string]int, len(sizeBuckets))
+	for _, bucket := range sizeBuckets {
+		histogram[bucket.label] = 0
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	c.engine.Range(func(key string, value any) bool {
+		size := c.size(c.unwrap(value))
+		for _, bucket := range sizeBuckets {
+			if size < bucket.limit {
+				histogram[bucket.label]++
+				break
+			}
+		}
+		return true
+	})
+
+	return histogram
+}
+
+// RejectedSets returns the number of writes dropped because the key or value
+// exceeded MaxKeyBytes or MaxValueBytes.
+func (c *Cache) RejectedSets() uint64 {
+	return c.rejectedSets.Load()
+}
+
+// storedSize returns the size in bytes of a value as stored: the length of
+// the compressed data for a compressed value, and its size as measured by
+// Sizer otherwise.
+func (c *Cache) storedSize(value any) int {
+	if entry, ok := value.(*callbackValue); ok {
+		value = entry.value
+	}
+	if computed, ok := value.(*computedValue); ok {
+		value = computed.value
+	}
+	if compressed, ok := value.(*compressedValue); ok {
+		return len(compressed.data)
+	}
+
+	return c.size(value)
+}
+
+// trackSize records the size of the entry stored under key, replacing the
+// size of its previous value, if Config.EstimateSize is set. The stored value
+// is measured by storedSize if size is unknownSize.
+func (c *Cache) trackSize(key string, value any, size int) {
+	if !c.config.EstimateSize {
+		return
+	}
+
+	if size == unknownSize {
+		size = c.storedSize(value)
+	}
+	size += len(key)
+
+	c.sizeLock.Lock()
+	defer c.sizeLock.Unlock()
+
+	if c.sizes == nil {
+		c.sizes = make(map[string]int)
+	}
+	c.estimatedBytes += size - c.sizes[key]
+	c.sizes[key] = size
+}
+
+// forgetSize drops the size of a removed entry.
+func (c *Cache) forgetSize(key string) {
+	if !c.config.EstimateSize {
+		return
+	}
+
+	c.sizeLock.Lock()
+	defer c.sizeLock.Unlock()
+
+	c.estimatedBytes -= c.sizes[key]
+	delete(c.sizes, key)
+}
+
+// resetSizes drops the sizes of every entry.
+func (c *Cache) resetSizes() {
+	c.sizeLock.Lock()
+	defer c.sizeLock.Unlock()
+
+	c.sizes = nil
+	c.estimatedBytes = 0
+}
+
+// EstimatedBytes returns the estimated footprint of the cached entries: the
+// sum of their key lengths and of their value sizes as measured by Sizer. It
+// requires Config.EstimateSize and returns 0 otherwise.
+//
+// The estimate covers the keys and values only, not the bookkeeping of the
+// eviction policy. With Config.Compress, compressed values count the size of
+// their compressed data.
+func (c *Cache) EstimatedBytes() uint64 {
+	c.sizeLock.Lock()
+	defer c.sizeLock.Unlock()
+
+	return uint64(c.estimatedBytes)
+}
@@ -0,0 +1,65 @@
+package cache
+
+// Snapshot is an immutable, point-in-time view of the entries of a cache,
+// returned by Cache.ReadOnlySnapshot. It is safe for concurrent use and never
+// reflects later changes to the cache.
+type Snapshot struct {
+	items     map[string]any
+	transform func(key string) string
+}
+
+// ReadOnlySnapshot copies the live entries of the cache into a Snapshot that
+// can be queried repeatedly without locking the cache.
+//
+// The entries are copied under the read lock, which blocks writers only for
+// the duration of the copy. Expired entries are not included. Values are
+// copied like for Get, so with CopyOnGet unset the snapshot shares them with
+// the cache and only stays stable as long as they are not mutated in place.
+func (c *Cache) ReadOnlySnapshot() *Snapshot {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return &Snapshot{items: c.entries(), transform: c.transformKey}
+}
+
+// Entries returns a map of all the live entries, e.g. to assert the full
+// contents of a cache in tests. Expired entries are excluded. The map is a
+// copy taken under the lock, so later writes to the cache don't change it.
+func (c *Cache) Entries() map[string]any {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.entries()
+}
+
+// entries returns a copy of the live entries. The caller must hold the lock.
+func (c *Cache) entries() map[string]any {
+	items := make(map[string]any, c.engine.Len())
+	c.engine.Range(func(key string, value any) bool {
+		items[key] = c.copyValue(c.unwrap(value))
+		return true
+	})
+
+	return items
+}
+
+// Get returns the value stored under key when the snapshot was taken.
+func (s *Snapshot) Get(key string) (any, bool) {
+	value, found := s.items[s.transform(key)]
+	return value, found
+}
+
+// Len returns the number of entries in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.items)
+}
+
+// Range calls fn for every entry of the snapshot, in no particular order,
+// until fn returns false.
+func (s *Snapshot) Range(fn func(key string, value any) bool) {
+	for key, value := range s.items {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import "time"
+
+// Stats combines the configuration and the runtime state of a cache, as
+// returned by Cache.Stats.
+type Stats struct {
+	Policy  EvictionPolicy
+	MaxSize int
+	TTL     time.Duration
+	Len     int
+
+	Hits        int64
+	Misses      int64
+	Expirations int64
+	Evictions   int64
+	HitRate     float64
+
+	MemoryLimits        uint64
+	MemoryCheckInterval time.Duration
+}
+
+// Stats returns the configuration and runtime state of the cache in a single
+// call. It is assembled under the cache lock, so the length and the metrics
+// are observed at the same point in time with regard to writes.
+func (c *Cache) Stats() Stats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	metrics := c.metrics.Snapshot()
+
+	return Stats{
+		Policy:              c.policy,
+		MaxSize:             c.config.MaxSize,
+		TTL:                 c.config.TTL,
+		Len:                 c.engine.Len(),
+		Hits:                metrics.Hits,
+		Misses:              metrics.Misses,
+		Expirations:         metrics.Expirations,
+		Evictions:           metrics.Evictions,
+		HitRate:             metrics.HitRate,
+		MemoryLimits:        c.config.MemoryLimits,
+		MemoryCheckInterval: c.config.MemoryCheckInterval,
+	}
+}
+
+// startMetricsLogger periodically passes the stats of the cache to
+// Config.MetricsLogger, every MetricsLogInterval, until the cache is closed.
+// It is only started by New if both are set.
+func (c *Cache) startMetricsLogger() {
+	ticker := time.NewTicker(c.config.MetricsLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			stats := c.Stats()
+			c.callback(func() { c.config.MetricsLogger(stats) })
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+// Tx gives access to the cache within WithLock. All its operations run under
+// the write lock held by WithLock, and it must not be used after fn returns.
+type Tx struct {
+	c *Cache
+}
+
+// WithLock runs fn with the write lock of the cache held, so the reads and
+// writes made through tx appear atomic to every other operation: no other
+// goroutine observes the cache between two of them.
+//
+// fn must only use tx and must not call methods of the cache itself, directly
+// or from a callback, as they would try to take the lock again and deadlock.
+// It should also be short, since it blocks every other reader and writer of
+// the cache while it runs.
+func (c *Cache) WithLock(fn func(tx Tx)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	fn(Tx{c: c})
+}
+
+// Get retrieves a value like Cache.Get, counting hits and misses, but without
+// calling OnHit or OnMiss.
+func (tx Tx) Get(key string) (any, bool) {
+	c := tx.c
+	key = c.transformKey(key)
+
+	value, found, expired := c.lookup(key)
+	if !found {
+		if expired {
+			c.deleteIfExpired(key)
+		}
+		c.recordMiss(key, expired)
+		return nil, false
+	}
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return value, true
+}
+
+// Set stores a key-value pair like Cache.Set. It returns false if the entry
+// is rejected by MaxKeyBytes or MaxValueBytes.
+func (tx Tx) Set(key string, value any) bool {
+	c := tx.c
+	key = c.transformKey(key)
+
+	if !c.accept(key, value) {
+		return false
+	}
+
+	c.set(key, value)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return true
+}
+
+// Delete removes a key like Cache.Delete.
+func (tx Tx) Delete(key string) {
+	c := tx.c
+	key = c.transformKey(key)
+
+	c.engine.Delete(key)
+	c.forget(key)
+}
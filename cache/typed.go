@@ -0,0 +1,21 @@
+package cache
+
+// GetTyped returns the value stored under key as a T, like Get followed by a
+// type assertion. It returns the zero value and false if the key is missing or
+// expired, or if the value has another type. Use GetAs to tell these cases
+// apart.
+func GetTyped[T any](c *Cache, key string) (T, bool) {
+	var zero T
+
+	value, found := c.Get(key)
+	if !found {
+		return zero, false
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, false
+	}
+
+	return typed, true
+}
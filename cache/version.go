@@ -0,0 +1,161 @@
+package cache
+
+// enableVersions starts tracking the version of every entry written from now
+// on. Entries stored before have version 0.
+func (c *Cache) enableVersions() {
+	c.versioned.Store(true)
+}
+
+// bumpVersion gives the entry stored under key a new version, if versions
+// are tracked, and returns it.
+func (c *Cache) bumpVersion(key string) uint64 {
+	if !c.versioned.Load() {
+		return 0
+	}
+
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	if c.versions == nil {
+		c.versions = make(map[string]uint64)
+	}
+	c.lastVersion++
+	c.versions[key] = c.lastVersion
+
+	return c.lastVersion
+}
+
+// versionOf returns the version of the entry stored under key.
+func (c *Cache) versionOf(key string) uint64 {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	return c.versions[key]
+}
+
+// forgetVersion drops the version of a removed entry.
+func (c *Cache) forgetVersion(key string) {
+	if !c.versioned.Load() {
+		return
+	}
+
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	delete(c.versions, key)
+}
+
+// resetVersions drops the versions of every entry. The version counter is
+// kept, so versions are never reused.
+func (c *Cache) resetVersions() {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+
+	c.versions = nil
+}
+
+// forget drops the size and the version of a removed entry, and wakes up
+// the WaitEmpty calls.
+func (c *Cache) forget(key string) {
+	c.forgetSize(key)
+	c.forgetVersion(key)
+	c.forgetInterned(key)
+	c.signalRemoval()
+}
+
+// forgetAll drops the sizes and the versions of every entry, and wakes up
+// the WaitEmpty calls.
+func (c *Cache) forgetAll() {
+	c.resetSizes()
+	c.resetVersions()
+	c.resetInterned()
+	c.signalRemoval()
+}
+
+// SetVersioned stores a key-value pair like Set and returns the version of
+// the new entry, or 0 if it was rejected by MaxKeyBytes or MaxValueBytes.
+//
+// Versions come from a counter shared by all the keys of the cache, so they
+// increase on every write and are never reused, even after a key is deleted
+// and stored again. Once any versioned method has been called, every write
+// (Set, SetWithDeadline, ...) assigns a new version.
+func (c *Cache) SetVersioned(key string, value any) uint64 {
+	c.enableVersions()
+	key = c.transformKey(key)
+
+	if !c.accept(key, value) {
+		return 0
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.set(key, value)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return c.versionOf(key)
+}
+
+// GetVersioned retrieves a value like Get, along with its version. Entries
+// stored before the first call to a versioned method have version 0.
+func (c *Cache) GetVersioned(key string) (value any, version uint64, ok bool) {
+	c.enableVersions()
+	key = c.transformKey(key)
+
+	c.lock.RLock()
+	value, ok, expired := c.lookup(key)
+	if ok {
+		version = c.versionOf(key)
+	}
+	c.lock.RUnlock()
+
+	if !ok {
+		if expired {
+			c.deleteExpired(key)
+		}
+		c.recordMiss(key, expired)
+		return nil, 0, false
+	}
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return value, version, true
+}
+
+// SetIfVersion stores a key-value pair only if the current version of the key
+// is expected, as returned by GetVersioned or SetVersioned, for optimistic
+// concurrency control. A missing or expired key has version 0, so an expected
+// version of 0 stores the value only if the key is absent.
+//
+// The check and the write happen atomically under the cache lock. It returns
+// false if the version does not match or the entry is rejected by MaxKeyBytes
+// or MaxValueBytes.
+func (c *Cache) SetIfVersion(key string, value any, expected uint64) bool {
+	c.enableVersions()
+	key = c.transformKey(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var current uint64
+	if c.engine.Has(key) {
+		current = c.versionOf(key)
+	}
+
+	if current != expected || !c.accept(key, value) {
+		return false
+	}
+
+	c.set(key, value)
+
+	if c.metricsEnabled.Load() {
+		c.metrics.IncrementHits()
+	}
+
+	return true
+}
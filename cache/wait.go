@@ -0,0 +1,58 @@
+package cache
+
+import "context"
+
+// WaitEmpty blocks until the cache holds no items, e.g. to let the entries
+// of a draining cache expire before a graceful shutdown. It returns nil once
+// the cache is empty, or the context error if ctx is done first.
+//
+// It does not poll: the cache length is checked again after every delete,
+// eviction, expiration or clear. Expired items count until the engine
+// removes them, which the Basic policy does on its periodic cleanup.
+func (c *Cache) WaitEmpty(ctx context.Context) error {
+	c.emptyWaiters.Add(1)
+	defer c.emptyWaiters.Add(-1)
+
+	stop := context.AfterFunc(ctx, func() {
+		c.emptyLock.Lock()
+		defer c.emptyLock.Unlock()
+
+		c.emptyCond.Broadcast()
+	})
+	defer stop()
+
+	for {
+		// The removal count is read before the length, so a removal between
+		// the two is not missed.
+		c.emptyLock.Lock()
+		seen := c.removals
+		c.emptyLock.Unlock()
+
+		if c.Len() == 0 {
+			return nil
+		}
+
+		c.emptyLock.Lock()
+		for c.removals == seen && ctx.Err() == nil {
+			c.emptyCond.Wait()
+		}
+		c.emptyLock.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// signalRemoval wakes up the WaitEmpty calls after items were removed.
+func (c *Cache) signalRemoval() {
+	if c.emptyWaiters.Load() == 0 {
+		return
+	}
+
+	c.emptyLock.Lock()
+	defer c.emptyLock.Unlock()
+
+	c.removals++
+	c.emptyCond.Broadcast()
+}
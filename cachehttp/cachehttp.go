@@ -0,0 +1,81 @@
+package cachehttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hugocarreira/easycache/cache"
+)
+
+// Options defines which endpoints are exposed by the handler.
+//
+// By default only the read-only stats endpoint is served. Listing keys and
+// flushing the cache must be enabled explicitly, since they may leak data or
+// destroy it.
+type Options struct {
+	// ExposeKeys enables `GET /cache/keys`, returning all keys stored in the cache.
+	ExposeKeys bool
+
+	// AllowFlush enables `DELETE /cache`, removing all items from the cache.
+	AllowFlush bool
+}
+
+// Stats is the JSON document returned by `GET /cache/stats`.
+type Stats struct {
+	Policy      string  `json:"policy"`
+	Len         int     `json:"len"`
+	Hits        int64   `json:"hits"`
+	Misses      int64   `json:"misses"`
+	Expirations int64   `json:"expirations"`
+	HitRate     float64 `json:"hit_rate"`
+}
+
+// Handler returns a read-only http.Handler exposing the cache stats at `/cache/stats`.
+func Handler(c *cache.Cache) http.Handler {
+	return NewHandler(c, Options{})
+}
+
+// NewHandler returns an http.Handler exposing the cache stats and the admin
+// operations enabled in opts.
+//
+// The following routes are served:
+//   - GET /cache/stats: hits, misses, expirations, hit rate, length and policy as JSON.
+//   - GET /cache/keys: the list of keys as JSON (requires ExposeKeys).
+//   - DELETE /cache: removes all items from the cache (requires AllowFlush).
+func NewHandler(c *cache.Cache, opts Options) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /cache/stats", func(w http.ResponseWriter, r *http.Request) {
+		stats := c.Stats()
+		writeJSON(w, Stats{
+			Policy:      stats.Policy.String(),
+			Len:         stats.Len,
+			Hits:        stats.Hits,
+			Misses:      stats.Misses,
+			Expirations: stats.Expirations,
+			HitRate:     stats.HitRate,
+		})
+	})
+
+	if opts.ExposeKeys {
+		mux.HandleFunc("GET /cache/keys", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, c.Keys())
+		})
+	}
+
+	if opts.AllowFlush {
+		mux.HandleFunc("DELETE /cache", func(w http.ResponseWriter, r *http.Request) {
+			c.Clear()
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,262 @@
+package clock
+
+import (
+	"container/list"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// Clock is a cache implementing the CLOCK (second-chance) replacement
+// algorithm, a variant of FIFO that spares recently used items.
+//
+// Items are kept in a circular buffer in insertion order, each with a
+// reference bit set whenever the item is accessed. To evict, a hand sweeps the
+// buffer from its current position: referenced items get a second chance (their
+// bit is cleared and the hand moves on), and the first item without the bit is
+// removed.
+//
+// Clock approximates LRU while keeping reads as cheap as in FIFO: an access only
+// sets a bit instead of reordering a list.
+type Clock struct {
+	maxSize int
+	data    map[string]*list.Element
+	buffer  *list.List
+	lock    sync.RWMutex
+
+	// hand is the next item examined by Evict. New items are inserted just
+	// behind it, so they are the last to be examined.
+	hand *list.Element
+}
+
+var (
+	_ engine.Engine           = (*Clock)(nil)
+	_ engine.Reserver         = (*Clock)(nil)
+	_ engine.SelectiveEvicter = (*Clock)(nil)
+)
+
+type cacheItem struct {
+	key        string
+	value      any
+	referenced bool
+}
+
+func New(maxSize int) engine.Engine {
+	return &Clock{
+		maxSize: maxSize,
+		data:    make(map[string]*list.Element),
+		buffer:  list.New(),
+	}
+}
+
+func (c *Clock) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return nil, false
+	}
+
+	item := elem.Value.(*cacheItem)
+	item.referenced = true
+
+	return item.value, true
+}
+
+func (c *Clock) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	return elem.Value.(*cacheItem).value, time.Time{}, true
+}
+
+func (c *Clock) Set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, exists := c.data[key]; exists {
+		item := elem.Value.(*cacheItem)
+		item.value = value
+		item.referenced = true
+		return
+	}
+
+	item := &cacheItem{key: key, value: value}
+	if c.hand == nil {
+		c.data[key] = c.buffer.PushBack(item)
+		c.hand = c.data[key]
+		return
+	}
+
+	c.data[key] = c.buffer.InsertBefore(item, c.hand)
+}
+
+func (c *Clock) SetWithTTL(key string, value any, expiresAt time.Time) {
+	c.Set(key, value)
+}
+
+func (c *Clock) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return
+	}
+
+	c.remove(elem)
+}
+
+func (c *Clock) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, exists := c.data[key]
+	return exists
+}
+
+func (c *Clock) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.data)
+}
+
+func (c *Clock) IsExpirable() bool {
+	return false
+}
+
+func (c *Clock) IsExpired(key string) bool {
+	return false
+}
+
+func (c *Clock) Touch(key string, expiresAt time.Time) bool {
+	return false
+}
+
+func (c *Clock) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.hand == nil {
+		return "", nil, false
+	}
+
+	// Every item is examined at most twice: once to clear its bit, then once
+	// more to be evicted.
+	for c.hand.Value.(*cacheItem).referenced {
+		c.hand.Value.(*cacheItem).referenced = false
+		c.hand = c.next(c.hand)
+	}
+
+	item := c.hand.Value.(*cacheItem)
+	c.remove(c.hand)
+
+	return item.key, item.value, true
+}
+
+// EvictFunc sweeps the buffer like Evict, giving referenced items a second
+// chance, and removes the first item without the bit accepted by accept. The
+// items it skips stay in place and the hand moves past them.
+func (c *Clock) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Two turns clear every bit and offer every item to accept once
+	for i := 0; c.hand != nil && i < 2*len(c.data); i++ {
+		item := c.hand.Value.(*cacheItem)
+		if !item.referenced && accept(item.key, item.value) {
+			c.remove(c.hand)
+			return item.key, item.value, true
+		}
+
+		item.referenced = false
+		c.hand = c.next(c.hand)
+	}
+
+	return "", nil, false
+}
+
+// Keys returns the keys in eviction order: the items without a reference bit
+// in the order the hand reaches them, then the referenced ones.
+func (c *Clock) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]string, 0, len(c.data))
+	var referenced []string
+
+	for i, elem := 0, c.hand; i < len(c.data); i, elem = i+1, c.next(elem) {
+		item := elem.Value.(*cacheItem)
+		if item.referenced {
+			referenced = append(referenced, item.key)
+			continue
+		}
+		keys = append(keys, item.key)
+	}
+
+	return append(keys, referenced...)
+}
+
+func (c *Clock) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, elem := range c.data {
+		if !fn(key, elem.Value.(*cacheItem).value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index to hold n items without rehashing.
+func (c *Clock) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*list.Element, n)
+	maps.Copy(data, c.data)
+	c.data = data
+}
+
+func (c *Clock) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*list.Element)
+	c.buffer.Init()
+	c.hand = nil
+}
+
+// next returns the item after elem in the circular buffer.
+func (c *Clock) next(elem *list.Element) *list.Element {
+	if next := elem.Next(); next != nil {
+		return next
+	}
+
+	return c.buffer.Front()
+}
+
+// remove deletes an item, moving the hand forward if it points to it.
+func (c *Clock) remove(elem *list.Element) {
+	if elem == c.hand {
+		c.hand = c.next(elem)
+		if c.hand == elem {
+			c.hand = nil
+		}
+	}
+
+	c.buffer.Remove(elem)
+	delete(c.data, elem.Value.(*cacheItem).key)
+}
@@ -11,12 +11,18 @@ type Engine interface {
 	// Returns (value, true) if the key exists, otherwise returns (nil, false).
 	Get(key string) (any, bool)
 
+	// Peek retrieves a value and its expiration time without updating the
+	// eviction order (recency or frequency). The expiration time is zero for
+	// caches that don't support TTL-based expiration.
+	Peek(key string) (any, time.Time, bool)
+
 	// Set stores a key-value pair in the cache.
 	// If the key already exists, its value is updated.
 	Set(key string, value any)
 
 	// SetWithTTL stores a key-value pair in the cache with an expiration time.
-	// This method is only relevant for TTL-based caches.
+	// This method is only relevant for TTL-based caches. A zero expiresAt means
+	// the item never expires.
 	SetWithTTL(key string, value any, expiresAt time.Time)
 
 	// Delete removes a key-value pair from the cache.
@@ -27,6 +33,11 @@ type Engine interface {
 	Has(key string) bool
 
 	// Len returns the number of items currently stored in the cache.
+	// For TTL-based caches, expired items are not counted, even before they
+	// are removed, so Len always matches the keys Has reports as present.
+	// It is called on every insertion of a new key, so it must not walk
+	// every item: the TTL-based engines keep their expiring items ordered by
+	// deadline and only visit the expired ones.
 	Len() int
 
 	// IsExpirable returns true if the cache supports TTL-based expiration.
@@ -35,6 +46,86 @@ type Engine interface {
 	// IsExpired checks whether a specific key has expired.
 	IsExpired(key string) bool
 
+	// Touch sets a new expiration time for an existing key without changing its value.
+	// Returns true if the key exists and has not expired. Always returns false for
+	// caches that don't support TTL-based expiration.
+	Touch(key string, expiresAt time.Time) bool
+
 	// Evict removes an item from the cache based on the eviction policy (FIFO, LRU, LFU).
-	Evict()
+	// Returns the evicted key and value and true, or ("", nil, false) if the cache is empty.
+	Evict() (string, any, bool)
+
+	// Keys returns the keys currently stored in the cache, ordered from the
+	// next to be evicted to the last. For TTL-based caches, expired keys are not included.
+	Keys() []string
+
+	// Range calls fn for every item in the cache, in no particular order, until
+	// fn returns false. For TTL-based caches, expired items are skipped. fn must
+	// not modify the cache.
+	Range(fn func(key string, value any) bool)
+
+	// Clear removes all items from the cache.
+	Clear()
+}
+
+// Weighted is implemented by engines that take an item's cost into account
+// when choosing which item to evict.
+//
+// Costly items (higher weight) are retained longer than cheap ones accessed
+// with the same frequency.
+type Weighted interface {
+	// SetWeighted stores a key-value pair in the cache with the given weight.
+	// If the key already exists, its value and weight are updated.
+	SetWeighted(key string, value any, weight float64)
+}
+
+// SelectiveEvicter is implemented by engines that can skip items when
+// evicting, e.g. pinned ones, without listing every key with Keys.
+type SelectiveEvicter interface {
+	// EvictFunc is like Evict, but only removes an item accepted by accept.
+	// accept is called on the items in eviction order until it returns true,
+	// and the engine state changes as for an Evict that reached that item. It
+	// returns false if no item was accepted. accept must not use the engine.
+	EvictFunc(accept func(key string, value any) bool) (string, any, bool)
+}
+
+// Closer is implemented by engines that run background goroutines, such as
+// the periodic cleanup of expired items.
+type Closer interface {
+	// Close stops the background goroutines. It is safe to call more than once.
+	Close()
+}
+
+// Reserver is implemented by engines that can preallocate their storage for
+// a known number of items, avoiding repeated growth while the cache fills.
+type Reserver interface {
+	// Reserve grows the storage to hold n items without reallocating. It is a
+	// hint: structures that cannot be preallocated, such as linked lists, still
+	// grow one item at a time.
+	Reserve(n int)
+}
+
+// ExpiryReporter is implemented by expirable engines that can tell, on a
+// read, an expired item from a missing one.
+type ExpiryReporter interface {
+	// GetOrExpired is like Get, but also reports whether the key is missing
+	// because its item has expired.
+	GetOrExpired(key string) (value any, found, expired bool)
+}
+
+// AccessTracker is implemented by engines that record when each item was last
+// read, to help investigate hot keys. The timestamps are informational and do
+// not take part in the eviction order.
+type AccessTracker interface {
+	// LastAccess returns the time the item was last read with Get, or the
+	// zero time if it was never read. It returns false if the key is missing.
+	LastAccess(key string) (time.Time, bool)
+}
+
+// StaleReader is implemented by engines that keep expired items for a grace
+// period, so they can still be served while a fresh value is computed.
+type StaleReader interface {
+	// GetStale is like Get, but also returns an item that expired less than
+	// the grace period ago, with stale set.
+	GetStale(key string) (value any, stale, found bool)
 }
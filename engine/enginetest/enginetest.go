@@ -0,0 +1,296 @@
+// Package enginetest provides a conformance suite for engine.Engine
+// implementations.
+//
+// Contributors adding an engine should run it from a test:
+//
+//	func TestMyEngine(t *testing.T) {
+//		enginetest.RunEngineConformance(t, func() engine.Engine {
+//			return myengine.New(0)
+//		})
+//	}
+package enginetest
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// RunEngineConformance checks that the engines returned by factory follow the
+// engine.Engine contract. factory must return a new, empty engine on each call.
+func RunEngineConformance(t *testing.T, factory func() engine.Engine) {
+	t.Helper()
+
+	t.Run("SetGet", func(t *testing.T) {
+		e := factory()
+
+		e.Set("A", "Item A")
+		expectValue(t, e, "A", "Item A")
+
+		e.Set("A", "Item A2")
+		expectValue(t, e, "A", "Item A2")
+
+		if _, found := e.Get("missing"); found {
+			t.Errorf("Get(%q) found a missing key", "missing")
+		}
+		if n := e.Len(); n != 1 {
+			t.Errorf("Len() = %d, want 1", n)
+		}
+	})
+
+	t.Run("Peek", func(t *testing.T) {
+		e := factory()
+		e.Set("A", "Item A")
+
+		value, _, found := e.Peek("A")
+		if !found || value != "Item A" {
+			t.Errorf("Peek(%q) = %v, %v, want %q, true", "A", value, found, "Item A")
+		}
+		if _, _, found := e.Peek("missing"); found {
+			t.Errorf("Peek(%q) found a missing key", "missing")
+		}
+	})
+
+	t.Run("DeleteHas", func(t *testing.T) {
+		e := factory()
+		e.Set("A", "Item A")
+		e.Set("B", "Item B")
+
+		if !e.Has("A") {
+			t.Errorf("Has(%q) = false, want true", "A")
+		}
+
+		e.Delete("A")
+		e.Delete("missing")
+
+		if e.Has("A") {
+			t.Errorf("Has(%q) = true after Delete", "A")
+		}
+		if _, found := e.Get("A"); found {
+			t.Errorf("Get(%q) found a deleted key", "A")
+		}
+		if n := e.Len(); n != 1 {
+			t.Errorf("Len() = %d after Delete, want 1", n)
+		}
+	})
+
+	t.Run("Evict", func(t *testing.T) {
+		e := factory()
+		if _, _, evicted := e.Evict(); evicted {
+			t.Errorf("Evict() on an empty engine returned true")
+		}
+
+		want := fill(e, 5)
+		for i := 5; i > 0; i-- {
+			next := e.Keys()[0]
+
+			key, value, evicted := e.Evict()
+			if !evicted {
+				t.Fatalf("Evict() returned false with %d items", i)
+			}
+			if key != next {
+				t.Errorf("Evict() removed %q, but Keys() announced %q", key, next)
+			}
+			if value != want[key] {
+				t.Errorf("Evict() returned %v for %q, want %v", value, key, want[key])
+			}
+			if e.Has(key) {
+				t.Errorf("Has(%q) = true after Evict", key)
+			}
+			if n := e.Len(); n != i-1 {
+				t.Errorf("Len() = %d after Evict, want %d", n, i-1)
+			}
+		}
+	})
+
+	t.Run("KeysRange", func(t *testing.T) {
+		e := factory()
+		want := fill(e, 10)
+
+		keys := e.Keys()
+		sort.Strings(keys)
+		if len(keys) != len(want) {
+			t.Fatalf("Keys() returned %d keys, want %d", len(keys), len(want))
+		}
+		for _, key := range keys {
+			if _, ok := want[key]; !ok {
+				t.Errorf("Keys() returned unknown key %q", key)
+			}
+		}
+
+		visited := make(map[string]any)
+		e.Range(func(key string, value any) bool {
+			visited[key] = value
+			return true
+		})
+		for key, value := range want {
+			if visited[key] != value {
+				t.Errorf("Range() visited %q with %v, want %v", key, visited[key], value)
+			}
+		}
+
+		calls := 0
+		e.Range(func(string, any) bool {
+			calls++
+			return false
+		})
+		if calls != 1 {
+			t.Errorf("Range() called fn %d times after it returned false, want 1", calls)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		e := factory()
+		fill(e, 5)
+
+		e.Clear()
+		if n := e.Len(); n != 0 {
+			t.Errorf("Len() = %d after Clear, want 0", n)
+		}
+		if keys := e.Keys(); len(keys) != 0 {
+			t.Errorf("Keys() = %v after Clear, want none", keys)
+		}
+
+		e.Set("A", "Item A")
+		expectValue(t, e, "A", "Item A")
+	})
+
+	t.Run("Reserve", func(t *testing.T) {
+		e := factory()
+		reserver, ok := e.(engine.Reserver)
+		if !ok {
+			t.Skip("engine does not implement engine.Reserver")
+		}
+
+		items := fill(e, 5)
+		reserver.Reserve(100)
+		if n := e.Len(); n != len(items) {
+			t.Errorf("Len() = %d after Reserve, want %d", n, len(items))
+		}
+		for key, value := range items {
+			expectValue(t, e, key, value)
+		}
+	})
+
+	t.Run("EvictFunc", func(t *testing.T) {
+		e := factory()
+		evicter, ok := e.(engine.SelectiveEvicter)
+		if !ok {
+			t.Skip("engine does not implement engine.SelectiveEvicter")
+		}
+
+		want := fill(e, 5)
+		order := e.Keys()
+
+		var offered []string
+		key, value, evicted := evicter.EvictFunc(func(key string, _ any) bool {
+			offered = append(offered, key)
+			return key == order[2]
+		})
+		if !evicted || key != order[2] || value != want[key] {
+			t.Errorf("EvictFunc() = %q, %v, %v, want %q, %v, true", key, value, evicted, order[2], want[order[2]])
+		}
+		if fmt.Sprint(offered) != fmt.Sprint(order[:3]) {
+			t.Errorf("EvictFunc() offered %v, want %v", offered, order[:3])
+		}
+		if e.Has(order[2]) {
+			t.Errorf("Has(%q) = true after EvictFunc", order[2])
+		}
+		if n := e.Len(); n != 4 {
+			t.Errorf("Len() = %d after EvictFunc, want 4", n)
+		}
+
+		if _, _, evicted := evicter.EvictFunc(func(string, any) bool { return false }); evicted {
+			t.Errorf("EvictFunc() evicted an item no call accepted")
+		}
+		if n := e.Len(); n != 4 {
+			t.Errorf("Len() = %d after a rejected EvictFunc, want 4", n)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		e := factory()
+
+		if !e.IsExpirable() {
+			e.SetWithTTL("A", "Item A", time.Now().Add(-time.Hour))
+			expectValue(t, e, "A", "Item A")
+
+			if e.IsExpired("A") {
+				t.Errorf("IsExpired(%q) = true on an engine without expiration", "A")
+			}
+			if e.Touch("A", time.Now().Add(time.Hour)) {
+				t.Errorf("Touch(%q) = true on an engine without expiration", "A")
+			}
+			return
+		}
+
+		e.SetWithTTL("expired", "value", time.Now().Add(-time.Hour))
+		if !e.IsExpired("expired") {
+			t.Errorf("IsExpired(%q) = false for a past expiration", "expired")
+		}
+		if e.Has("expired") {
+			t.Errorf("Has(%q) = true for an expired key", "expired")
+		}
+		if _, found := e.Get("expired"); found {
+			t.Errorf("Get(%q) found an expired key", "expired")
+		}
+
+		e.SetWithTTL("forever", "value", time.Time{})
+		if e.IsExpired("forever") {
+			t.Errorf("IsExpired(%q) = true for a zero expiration", "forever")
+		}
+		expectValue(t, e, "forever", "value")
+
+		e.SetWithTTL("touched", "value", time.Now().Add(time.Hour))
+		expiresAt := time.Now().Add(2 * time.Hour)
+		if !e.Touch("touched", expiresAt) {
+			t.Errorf("Touch(%q) = false for a live key", "touched")
+		}
+		if _, got, _ := e.Peek("touched"); !got.Equal(expiresAt) {
+			t.Errorf("Peek(%q) expiration = %v after Touch, want %v", "touched", got, expiresAt)
+		}
+		if n := e.Len(); n != 2 {
+			t.Errorf("Len() = %d with an expired key, want 2", n)
+		}
+
+		e.SetWithTTL("soon", "value", time.Now().Add(10*time.Millisecond))
+		if n := e.Len(); n != 3 {
+			t.Errorf("Len() = %d before the expiration, want 3", n)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if n := e.Len(); n != 2 {
+			t.Errorf("Len() = %d after the expiration, want 2", n)
+		}
+		if n := len(e.Keys()); n != e.Len() {
+			t.Errorf("len(Keys()) = %d, Len() = %d", n, e.Len())
+		}
+		if e.Touch("missing", expiresAt) {
+			t.Errorf("Touch(%q) = true for a missing key", "missing")
+		}
+	})
+}
+
+// fill stores n items and returns them.
+func fill(e engine.Engine, n int) map[string]any {
+	items := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		items[key] = fmt.Sprintf("value-%d", i)
+		e.Set(key, items[key])
+	}
+
+	return items
+}
+
+// expectValue checks that key is stored with value.
+func expectValue(t *testing.T, e engine.Engine, key string, value any) {
+	t.Helper()
+
+	got, found := e.Get(key)
+	if !found || got != value {
+		t.Errorf("Get(%q) = %v, %v, want %v, true", key, got, found, value)
+	}
+}
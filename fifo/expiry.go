@@ -0,0 +1,80 @@
+package fifo
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryQueue is a min-heap of the items that expire, ordered by expiration
+// time.
+type expiryQueue []*cacheItem
+
+func (q expiryQueue) Len() int { return len(q) }
+
+func (q expiryQueue) Less(i, j int) bool { return q[i].expiresAt.Before(q[j].expiresAt) }
+
+func (q expiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expiryQueue) Push(x any) {
+	item := x.(*cacheItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *expiryQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	item.index = -1
+	*q = old[:len(old)-1]
+	return item
+}
+
+// schedule places an item in the expiry queue at its expiration time, or
+// takes it out if it never expires. The caller must hold the write lock.
+func (c *FIFO) schedule(item *cacheItem) {
+	if item.expiresAt.IsZero() {
+		c.unschedule(item)
+		return
+	}
+
+	if item.index >= 0 {
+		heap.Fix(&c.expiries, item.index)
+		return
+	}
+	heap.Push(&c.expiries, item)
+}
+
+// unschedule takes an item out of the expiry queue. The caller must hold the
+// write lock.
+func (c *FIFO) unschedule(item *cacheItem) {
+	if item.index >= 0 {
+		heap.Remove(&c.expiries, item.index)
+	}
+}
+
+// mayHaveExpired reports whether an item has expired at the given time. The
+// caller must hold the lock.
+func (c *FIFO) mayHaveExpired(now time.Time) bool {
+	return len(c.expiries) > 0 && c.expiries[0].expired(now)
+}
+
+// expire removes the items expired at the given time and returns copies of
+// them. It only visits the expired items, so it costs O(log n) per item
+// removed, however many items the cache holds. The caller must hold the
+// write lock.
+func (c *FIFO) expire(now time.Time) []cacheItem {
+	var expired []cacheItem
+
+	for c.mayHaveExpired(now) {
+		item := c.expiries[0]
+		expired = append(expired, cacheItem{key: item.key, value: item.value})
+		c.remove(c.data[item.key])
+	}
+
+	return expired
+}
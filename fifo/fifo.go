@@ -2,6 +2,7 @@ package fifo
 
 import (
 	"container/list"
+	"maps"
 	"sync"
 	"time"
 
@@ -14,6 +15,13 @@ import (
 // This eviction policy ensures that the first item added is the first one to be removed,
 // regardless of how frequently or recently it was accessed.
 //
+// Items stored with SetWithTTL also expire by time, which makes FIFO suited to
+// time-windowed caches such as rate limiting: the oldest item is evicted on
+// capacity, and expired items are hidden from reads. FIFO has no periodic
+// cleanup: expired items are removed when the cache is measured by Len, which
+// Cache does on every insertion of a new key, when they are evicted, or when
+// they are read if DeleteOnRead is set.
+//
 // FIFO is useful for scenarios where older data should be discarded in favor of newer data,
 // such as caching queue-like structures.
 type FIFO struct {
@@ -21,49 +29,198 @@ type FIFO struct {
 	data         map[string]*list.Element
 	evictionList *list.List
 	lock         sync.RWMutex
+	clock        func() time.Time
+	onExpire     func(key string, value any)
+	deleteOnRead bool
+
+	// expiries holds the items that expire, ordered by expiration time, so
+	// that Len only visits the expired ones.
+	expiries expiryQueue
+}
+
+var (
+	_ engine.Engine           = (*FIFO)(nil)
+	_ engine.Reserver         = (*FIFO)(nil)
+	_ engine.ExpiryReporter   = (*FIFO)(nil)
+	_ engine.SelectiveEvicter = (*FIFO)(nil)
+)
+
+// Options defines the settings used to build a FIFO cache.
+type Options struct {
+	// MaxSize is the maximum number of items the cache can hold.
+	MaxSize int
+
+	// OnExpire, if set, is called for every expired item removed from the
+	// cache. It is called without holding the cache lock.
+	OnExpire func(key string, value any)
+
+	// Clock, if set, replaces time.Now as the source of the current time.
+	Clock func() time.Time
+
+	// DeleteOnRead makes Get remove an expired item right away, which takes the
+	// write lock on the read path. By default, Get only reports a miss and the
+	// item is removed by the next Len or eviction.
+	DeleteOnRead bool
 }
 
 type cacheItem struct {
 	key   string
 	value any
+
+	// expiresAt is the expiration time of the item. The zero time means the
+	// item never expires.
+	expiresAt time.Time
+
+	// index is the position of the item in the expiry queue, or -1 if it
+	// never expires.
+	index int
+}
+
+// expired reports whether the item has expired at the given time.
+func (i *cacheItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// itemPool recycles cacheItem structs released on Delete and Evict,
+// reducing allocations under high churn.
+var itemPool = sync.Pool{
+	New: func() any {
+		return new(cacheItem)
+	},
+}
+
+// newItem returns a cacheItem from the pool.
+func newItem(key string, value any) *cacheItem {
+	item := itemPool.Get().(*cacheItem)
+	item.key = key
+	item.value = value
+	item.index = -1
+	return item
+}
+
+// releaseItem resets an item and returns it to the pool. The item must no
+// longer be referenced by the cache.
+func releaseItem(item *cacheItem) {
+	*item = cacheItem{}
+	itemPool.Put(item)
 }
 
 func New(maxSize int) engine.Engine {
+	return NewWithOptions(Options{MaxSize: maxSize})
+}
+
+// NewWithOptions creates a FIFO cache with the given options.
+func NewWithOptions(opts Options) engine.Engine {
 	return &FIFO{
-		maxSize:      maxSize,
+		maxSize:      opts.MaxSize,
 		data:         make(map[string]*list.Element),
 		evictionList: list.New(),
+		clock:        opts.Clock,
+		onExpire:     opts.OnExpire,
+		deleteOnRead: opts.DeleteOnRead,
 	}
 }
 
+// now returns the current time from the configured clock.
+func (c *FIFO) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	return time.Now()
+}
+
 func (c *FIFO) Get(key string) (any, bool) {
+	value, found, _ := c.GetOrExpired(key)
+	return value, found
+}
+
+func (c *FIFO) GetOrExpired(key string) (any, bool, bool) {
+	c.lock.RLock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		c.lock.RUnlock()
+		return nil, false, false
+	}
+
+	item := elem.Value.(*cacheItem)
+	if item.expired(c.now()) {
+		c.lock.RUnlock()
+		if c.deleteOnRead {
+			c.deleteExpired(key)
+		}
+		return nil, false, true
+	}
+
+	value := item.value
+	c.lock.RUnlock()
+	return value, true, false
+}
+
+// deleteExpired removes key if it is still expired once the write lock is held.
+func (c *FIFO) deleteExpired(key string) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
+	elem, exists := c.data[key]
+	if !exists || !elem.Value.(*cacheItem).expired(c.now()) {
+		c.lock.Unlock()
+		return
+	}
+	item := elem.Value.(*cacheItem)
+	key, value := item.key, item.value
+	c.remove(elem)
+	c.lock.Unlock()
+
+	c.notifyExpired(key, value)
+}
+
+func (c *FIFO) notifyExpired(key string, value any) {
+	if c.onExpire != nil {
+		c.onExpire(key, value)
+	}
+}
+
+func (c *FIFO) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 
 	elem, exists := c.data[key]
 	if !exists {
-		return nil, false
+		return nil, time.Time{}, false
+	}
+
+	item := elem.Value.(*cacheItem)
+	if item.expired(c.now()) {
+		return nil, time.Time{}, false
 	}
 
-	return elem.Value.(*cacheItem).value, true
+	return item.value, item.expiresAt, true
 }
 
+// Set stores an item that never expires.
 func (c *FIFO) Set(key string, value any) {
+	c.SetWithTTL(key, value, time.Time{})
+}
+
+// SetWithTTL stores an item expiring at expiresAt. Updating an existing key
+// keeps its position in the queue.
+func (c *FIFO) SetWithTTL(key string, value any, expiresAt time.Time) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	if elem, exists := c.data[key]; exists {
-		elem.Value.(*cacheItem).value = value
+		item := elem.Value.(*cacheItem)
+		item.value = value
+		item.expiresAt = expiresAt
+		c.schedule(item)
 		return
 	}
 
-	item := &cacheItem{key: key, value: value}
+	item := newItem(key, value)
+	item.expiresAt = expiresAt
 	elem := c.evictionList.PushBack(item)
 	c.data[key] = elem
-}
-
-func (c *FIFO) SetWithTTL(key string, value any, expiresAt time.Time) {
-	c.Set(key, value)
+	c.schedule(item)
 }
 
 func (c *FIFO) Delete(key string) {
@@ -75,44 +232,173 @@ func (c *FIFO) Delete(key string) {
 		return
 	}
 
+	c.remove(elem)
+}
+
+// remove deletes an item from the cache. The caller must hold the write lock.
+func (c *FIFO) remove(elem *list.Element) {
+	item := elem.Value.(*cacheItem)
 	c.evictionList.Remove(elem)
-	delete(c.data, key)
+	delete(c.data, item.key)
+	c.unschedule(item)
+	releaseItem(item)
 }
 
 func (c *FIFO) Has(key string) bool {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	_, exists := c.data[key]
-	return exists
+	elem, exists := c.data[key]
+	return exists && !elem.Value.(*cacheItem).expired(c.now())
 }
 
+// Len returns the number of items that have not expired. It is O(1) as long
+// as no item has expired since the last call; otherwise the expired items are
+// removed first. The items are kept ordered by expiration time, so only the
+// expired ones are visited.
 func (c *FIFO) Len() int {
 	c.lock.RLock()
-	defer c.lock.RUnlock()
-	return len(c.data)
+	now := c.now()
+	if !c.mayHaveExpired(now) {
+		defer c.lock.RUnlock()
+		return len(c.data)
+	}
+	c.lock.RUnlock()
+
+	c.lock.Lock()
+	expired := c.expire(now)
+	n := len(c.data)
+	c.lock.Unlock()
+
+	for _, item := range expired {
+		c.notifyExpired(item.key, item.value)
+	}
+
+	return n
 }
 
 func (c *FIFO) IsExpirable() bool {
-	return false
+	return true
 }
 
 func (c *FIFO) IsExpired(key string) bool {
-	return false
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return true
+	}
+
+	return elem.Value.(*cacheItem).expired(c.now())
 }
 
-func (c *FIFO) Evict() {
+func (c *FIFO) Touch(key string, expiresAt time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return false
+	}
+
+	item := elem.Value.(*cacheItem)
+	if item.expired(c.now()) {
+		return false
+	}
+
+	item.expiresAt = expiresAt
+	c.schedule(item)
+	return true
+}
+
+func (c *FIFO) Evict() (string, any, bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	if len(c.data) == 0 {
-		return
+		return "", nil, false
 	}
 
 	elem := c.evictionList.Front()
-	if elem != nil {
+	if elem == nil {
+		return "", nil, false
+	}
+
+	item := elem.Value.(*cacheItem)
+	key, value := item.key, item.value
+	c.remove(elem)
+
+	return key, value, true
+}
+
+// EvictFunc removes the oldest item among the ones accepted by accept.
+func (c *FIFO) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for elem := c.evictionList.Front(); elem != nil; elem = elem.Next() {
+		item := elem.Value.(*cacheItem)
+		if accept(item.key, item.value) {
+			key, value := item.key, item.value
+			c.remove(elem)
+			return key, value, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (c *FIFO) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]string, 0, len(c.data))
+	now := c.now()
+	for elem := c.evictionList.Front(); elem != nil; elem = elem.Next() {
+		if item := elem.Value.(*cacheItem); !item.expired(now) {
+			keys = append(keys, item.key)
+		}
+	}
+
+	return keys
+}
+
+func (c *FIFO) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	now := c.now()
+	for key, elem := range c.data {
 		item := elem.Value.(*cacheItem)
-		delete(c.data, item.key)
-		c.evictionList.Remove(elem)
+		if item.expired(now) {
+			continue
+		}
+		if !fn(key, item.value) {
+			return
+		}
 	}
 }
+
+// Reserve grows the key index to hold n items without rehashing.
+func (c *FIFO) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*list.Element, n)
+	maps.Copy(data, c.data)
+	c.data = data
+}
+
+func (c *FIFO) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*list.Element)
+	c.evictionList.Init()
+	c.expiries = nil
+}
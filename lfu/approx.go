@@ -0,0 +1,139 @@
+package lfu
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// Approx is an LFU cache that estimates access frequencies with a count-min
+// sketch instead of counting the accesses of every key exactly.
+//
+// The sketch has a fixed size derived from maxSize, or from
+// unboundedSketchKeys for an unbounded cache, and grown by Reserve. It also
+// remembers keys that were evicted, so a key coming back resumes its estimated
+// frequency instead of starting from 1, while the frequency metadata of the
+// keys no longer cached stays bounded. Collisions in the sketch may
+// overestimate a frequency, which trades some eviction accuracy for memory.
+// Frequencies are periodically halved so old popularity fades.
+//
+// Only the frequency metadata is bounded: each stored item still costs what it
+// costs in LFU, a map entry and a list element holding its key, value, bucket
+// and access times.
+//
+// Items are ordered like in LFU, using the estimated frequency, with the least
+// recently used evicted first among equal estimates.
+type Approx struct {
+	*LFU
+	sketch *countMinSketch
+}
+
+var (
+	_ engine.Engine   = (*Approx)(nil)
+	_ engine.Reserver = (*Approx)(nil)
+)
+
+// unboundedSketchKeys is the number of distinct keys the sketch of an
+// unbounded cache is sized for, about 1 MiB of counters, so that frequencies
+// don't collide on a minimal sketch. Reserve sizes it for more.
+const unboundedSketchKeys = 1 << 16
+
+// NewApprox creates an LFU cache with approximate frequency counters.
+func NewApprox(maxSize int) engine.Engine {
+	keys := maxSize
+	if keys <= 0 {
+		keys = unboundedSketchKeys
+	}
+
+	return &Approx{
+		LFU:    New(maxSize).(*LFU),
+		sketch: newCountMinSketch(keys),
+	}
+}
+
+func (c *Approx) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return nil, false
+	}
+
+	item := elem.Value.(*listItem)
+	c.access(elem)
+	item.lastAccess = c.now()
+
+	return item.value, true
+}
+
+func (c *Approx) Set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, exists := c.data[key]; exists {
+		elem.Value.(*listItem).value = value
+		c.access(elem)
+		return
+	}
+
+	c.insert(key, value, c.record(key))
+	c.data[key].Value.(*listItem).countedAt = c.window.start()
+}
+
+// access counts an access to an item, unless it is within the count window
+// of the last counted one, and moves it to the bucket of its estimated
+// frequency. The caller must hold the write lock.
+func (c *Approx) access(elem *list.Element) {
+	item := elem.Value.(*listItem)
+	if !c.window.counts(&item.countedAt) {
+		item.bucket.Value.(*frequencyBucket).items.MoveToFront(elem)
+		return
+	}
+
+	c.place(elem, c.record(item.key))
+}
+
+func (c *Approx) SetWithTTL(key string, value any, expiresAt time.Time) {
+	c.Set(key, value)
+}
+
+func (c *Approx) Clear() {
+	c.LFU.Clear()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sketch.Reset()
+}
+
+// Reserve grows the key index to hold n items without rehashing, and the
+// sketch to n distinct keys if it was sized for fewer. A grown sketch starts
+// from the frequencies of the stored items, and forgets the evicted keys.
+func (c *Approx) Reserve(n int) {
+	c.LFU.Reserve(n)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= c.sketch.Keys() {
+		return
+	}
+
+	c.sketch = newCountMinSketch(n)
+	for key, elem := range c.data {
+		item := elem.Value.(*listItem)
+		c.sketch.Seed(key, item.bucket.Value.(*frequencyBucket).frequency)
+	}
+}
+
+// record counts an access to key and returns its estimated frequency. The
+// caller must hold the write lock.
+func (c *Approx) record(key string) int {
+	if c.sketch.Increment(key) {
+		c.halve()
+	}
+
+	return max(c.sketch.Estimate(key), 1)
+}
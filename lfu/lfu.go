@@ -1,7 +1,10 @@
 package lfu
 
 import (
-	"container/heap"
+	"container/list"
+	"maps"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/hugocarreira/easycache/engine"
@@ -13,56 +16,175 @@ import (
 // Each item in the cache maintains a usage counter that increments every time the item is accessed.
 // When eviction is necessary, the item with the lowest usage count is removed.
 //
+// Items are grouped in frequency buckets kept in ascending order, each holding
+// its items from most to least recently used. This makes Get, Set and Evict
+// O(1). When several items share the lowest frequency, the least recently used
+// among them is evicted first, which keeps eviction deterministic.
+//
+// Frequencies saturate at a ceiling (see SetMaxFrequency), so a long-lived hot
+// key cannot overflow its counter; items at the ceiling are ordered by recency.
+//
 // LFU is useful for scenarios where frequently accessed items should be retained
 // while less important data is discarded.
 type LFU struct {
 	maxSize int
-	data    map[string]*cacheItem
-	lfuHeap *lfuHeap
+	data    map[string]*list.Element
+
+	// buckets holds a *frequencyBucket per distinct frequency, in ascending order.
+	buckets *list.List
+
+	// maxFrequency is the ceiling at which frequencies stop increasing.
+	maxFrequency int
+
+	// window debounces the frequency increments (see SetCountWindow).
+	window countWindow
+
+	// clock times the accesses reported by LastAccess (see SetClock).
+	clock func() time.Time
+
+	lock sync.RWMutex
 }
 
-type cacheItem struct {
-	key       string
-	value     any
+var (
+	_ engine.Engine           = (*LFU)(nil)
+	_ engine.Reserver         = (*LFU)(nil)
+	_ engine.AccessTracker    = (*LFU)(nil)
+	_ engine.SelectiveEvicter = (*LFU)(nil)
+)
+
+// DefaultMaxFrequency is the default frequency ceiling. It fits in 32 bits, so
+// frequencies cannot overflow on any platform.
+const DefaultMaxFrequency = math.MaxInt32
+
+type frequencyBucket struct {
 	frequency int
-	index     int
+	items     *list.List
 }
 
-func New(maxSize int) engine.Engine {
-	l := &lfuHeap{}
-	heap.Init(l)
+type listItem struct {
+	key    string
+	value  any
+	bucket *list.Element
+
+	// lastAccess is the time of the last Get, or the zero time.
+	lastAccess time.Time
+
+	// countedAt is the time of the last access that incremented the
+	// frequency, with SetCountWindow.
+	countedAt time.Time
+}
 
+func New(maxSize int) engine.Engine {
 	return &LFU{
-		maxSize: maxSize,
-		data:    make(map[string]*cacheItem),
-		lfuHeap: l,
+		maxSize:      maxSize,
+		data:         make(map[string]*list.Element),
+		buckets:      list.New(),
+		maxFrequency: DefaultMaxFrequency,
+	}
+}
+
+// SetMaxFrequency sets the ceiling at which frequencies stop increasing. An
+// access to an item at the ceiling only refreshes its recency. A value of 0 or
+// less restores DefaultMaxFrequency. It should be called before the cache is used.
+func (c *LFU) SetMaxFrequency(maxFrequency int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if maxFrequency <= 0 {
+		maxFrequency = DefaultMaxFrequency
+	}
+
+	c.maxFrequency = maxFrequency
+}
+
+// SetCountWindow makes accesses to an item within window of its last counted
+// access only refresh its recency, without incrementing its frequency. The
+// accesses are timed with now, or time.Now if nil. A window of 0 counts every
+// access. It should be called before the cache is used.
+func (c *LFU) SetCountWindow(window time.Duration, now func() time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.window.set(window, now)
+}
+
+// SetClock makes the access times reported by LastAccess come from now
+// instead of time.Now. It should be called before the cache is used.
+func (c *LFU) SetClock(now func() time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.clock = now
+}
+
+// now returns the current time from the configured clock.
+func (c *LFU) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
 	}
+
+	return time.Now()
 }
 
 func (c *LFU) Get(key string) (any, bool) {
-	item, exists := c.data[key]
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
 	if !exists {
 		return nil, false
 	}
 
-	item.frequency++
-	heap.Fix(c.lfuHeap, item.index)
+	c.increment(elem)
 
+	item := elem.Value.(*listItem)
+	item.lastAccess = c.now()
 	return item.value, true
 }
 
+// LastAccess returns the time the item was last read with Get, or the zero
+// time if it was never read. It returns false if the key is missing.
+func (c *LFU) LastAccess(key string) (time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	return elem.Value.(*listItem).lastAccess, true
+}
+
+func (c *LFU) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	return elem.Value.(*listItem).value, time.Time{}, true
+}
+
 func (c *LFU) Set(key string, value any) {
-	if item, exists := c.data[key]; exists {
-		item.value = value
-		item.frequency++
-		heap.Fix(c.lfuHeap, item.index)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, exists := c.data[key]; exists {
+		elem.Value.(*listItem).value = value
+		c.increment(elem)
 		return
 	}
 
-	item := &cacheItem{key: key, value: value, frequency: 1}
-	heap.Push(c.lfuHeap, item)
-	item.index = c.lfuHeap.Len() - 1
-	c.data[key] = item
+	first := c.buckets.Front()
+	if first == nil || first.Value.(*frequencyBucket).frequency != 1 {
+		first = c.buckets.PushFront(&frequencyBucket{frequency: 1, items: list.New()})
+	}
+
+	item := &listItem{key: key, value: value, bucket: first, countedAt: c.window.start()}
+	c.data[key] = first.Value.(*frequencyBucket).items.PushFront(item)
 }
 
 func (c *LFU) SetWithTTL(key string, value any, expiresAt time.Time) {
@@ -70,21 +192,30 @@ func (c *LFU) SetWithTTL(key string, value any, expiresAt time.Time) {
 }
 
 func (c *LFU) Delete(key string) {
-	item, exists := c.data[key]
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, exists := c.data[key]
 	if !exists {
 		return
 	}
 
-	heap.Remove(c.lfuHeap, item.index)
+	c.unlink(elem)
 	delete(c.data, key)
 }
 
 func (c *LFU) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	_, exists := c.data[key]
 	return exists
 }
 
 func (c *LFU) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return len(c.data)
 }
 
@@ -96,42 +227,230 @@ func (c *LFU) IsExpired(key string) bool {
 	return false
 }
 
-func (c *LFU) Evict() {
-	if len(c.data) == 0 {
-		return
+func (c *LFU) Touch(key string, expiresAt time.Time) bool {
+	return false
+}
+
+func (c *LFU) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	first := c.buckets.Front()
+	if first == nil {
+		return "", nil, false
 	}
 
-	item := heap.Pop(c.lfuHeap).(*cacheItem)
+	elem := first.Value.(*frequencyBucket).items.Back()
+	item := elem.Value.(*listItem)
+	c.unlink(elem)
 	delete(c.data, item.key)
+
+	return item.key, item.value, true
+}
+
+// EvictFunc removes the least frequently used item among the ones accepted
+// by accept, the least recently used one among equal frequencies.
+func (c *LFU) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for b := c.buckets.Front(); b != nil; b = b.Next() {
+		items := b.Value.(*frequencyBucket).items
+		for elem := items.Back(); elem != nil; elem = elem.Prev() {
+			item := elem.Value.(*listItem)
+			if accept(item.key, item.value) {
+				c.unlink(elem)
+				delete(c.data, item.key)
+				return item.key, item.value, true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+func (c *LFU) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]string, 0, len(c.data))
+	for b := c.buckets.Front(); b != nil; b = b.Next() {
+		items := b.Value.(*frequencyBucket).items
+		for elem := items.Back(); elem != nil; elem = elem.Prev() {
+			keys = append(keys, elem.Value.(*listItem).key)
+		}
+	}
+
+	return keys
+}
+
+func (c *LFU) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, elem := range c.data {
+		if !fn(key, elem.Value.(*listItem).value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index to hold n items without rehashing.
+func (c *LFU) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*list.Element, n)
+	maps.Copy(data, c.data)
+	c.data = data
+}
+
+func (c *LFU) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*list.Element)
+	c.buckets.Init()
 }
 
-type lfuHeap []*cacheItem
+// ToWeighted returns a Weighted engine ordering items by score, or
+// DefaultScore if nil, holding the items of c with their frequencies and a
+// weight of 1, in the same eviction order. The frequency ceiling and count
+// window carry over. It lets a cache start with the O(1) engine and switch
+// once weights are used; c must not be used afterwards.
+func (c *LFU) ToWeighted(score ScoreFunc) *Weighted {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-func (l lfuHeap) Len() int {
-	return len(l)
+	w := NewWithScore(c.maxSize, score).(*Weighted)
+	w.maxFrequency = c.maxFrequency
+	w.window = c.window
+	w.Reserve(len(c.data))
+
+	for b := c.buckets.Front(); b != nil; b = b.Next() {
+		bucket := b.Value.(*frequencyBucket)
+		for elem := bucket.items.Back(); elem != nil; elem = elem.Prev() {
+			item := elem.Value.(*listItem)
+			moved := &cacheItem{key: item.key, value: item.value, frequency: bucket.frequency, weight: 1}
+			w.push(moved)
+			moved.countedAt = item.countedAt
+		}
+	}
+
+	return w
 }
 
-func (l lfuHeap) Less(i, j int) bool {
-	return l[i].frequency < l[j].frequency
+// increment moves an item to the bucket of the next frequency. At the
+// frequency ceiling, or within the count window, the item stays in its bucket
+// as the most recently used.
+func (c *LFU) increment(elem *list.Element) {
+	item := elem.Value.(*listItem)
+	current := item.bucket
+	if current.Value.(*frequencyBucket).frequency >= c.maxFrequency || !c.window.counts(&item.countedAt) {
+		current.Value.(*frequencyBucket).items.MoveToFront(elem)
+		return
+	}
+	frequency := current.Value.(*frequencyBucket).frequency + 1
+
+	next := current.Next()
+	if next == nil || next.Value.(*frequencyBucket).frequency != frequency {
+		next = c.buckets.InsertAfter(&frequencyBucket{frequency: frequency, items: list.New()}, current)
+	}
+
+	c.unlink(elem)
+	item.bucket = next
+	c.data[item.key] = next.Value.(*frequencyBucket).items.PushFront(item)
+}
+
+// insert adds a new item to the bucket of the given frequency, capped at the
+// frequency ceiling.
+func (c *LFU) insert(key string, value any, frequency int) {
+	bucket := c.bucketFor(nil, min(frequency, c.maxFrequency))
+	item := &listItem{key: key, value: value, bucket: bucket}
+	c.data[key] = bucket.Value.(*frequencyBucket).items.PushFront(item)
+}
+
+// place moves an item to the bucket of the given frequency, capped at the
+// frequency ceiling, as the most recently used item of that bucket.
+func (c *LFU) place(elem *list.Element, frequency int) {
+	item := elem.Value.(*listItem)
+	frequency = min(frequency, c.maxFrequency)
+
+	from := item.bucket
+	if frequency == from.Value.(*frequencyBucket).frequency {
+		from.Value.(*frequencyBucket).items.MoveToFront(elem)
+		return
+	}
+	if frequency < from.Value.(*frequencyBucket).frequency {
+		from = nil
+	}
+	target := c.bucketFor(from, frequency)
+
+	c.unlink(elem)
+	item.bucket = target
+	c.data[item.key] = target.Value.(*frequencyBucket).items.PushFront(item)
 }
 
-func (l lfuHeap) Swap(i, j int) {
-	l[i], l[j] = l[j], l[i]
-	l[i].index = i
-	l[j].index = j
+// bucketFor returns the bucket of the given frequency, creating it if needed.
+// The search starts at from, or at the lowest frequency if from is nil.
+func (c *LFU) bucketFor(from *list.Element, frequency int) *list.Element {
+	if from == nil {
+		from = c.buckets.Front()
+		if from == nil || from.Value.(*frequencyBucket).frequency > frequency {
+			return c.buckets.PushFront(&frequencyBucket{frequency: frequency, items: list.New()})
+		}
+	}
+
+	for {
+		if from.Value.(*frequencyBucket).frequency == frequency {
+			return from
+		}
+
+		next := from.Next()
+		if next == nil || next.Value.(*frequencyBucket).frequency > frequency {
+			return c.buckets.InsertAfter(&frequencyBucket{frequency: frequency, items: list.New()}, from)
+		}
+		from = next
+	}
 }
 
-func (l *lfuHeap) Push(x any) {
-	n := len(*l)
-	item := x.(*cacheItem)
-	item.index = n
-	*l = append(*l, item)
+// halve divides every frequency by two, merging the buckets that end up with
+// the same frequency. Items from the higher bucket are kept as the more
+// recently used ones.
+func (c *LFU) halve() {
+	var prev *list.Element
+	for b := c.buckets.Front(); b != nil; {
+		next := b.Next()
+		bucket := b.Value.(*frequencyBucket)
+		bucket.frequency /= 2
+
+		if prev != nil && prev.Value.(*frequencyBucket).frequency == bucket.frequency {
+			into := prev.Value.(*frequencyBucket).items
+			for elem := bucket.items.Back(); elem != nil; elem = elem.Prev() {
+				item := elem.Value.(*listItem)
+				item.bucket = prev
+				c.data[item.key] = into.PushFront(item)
+			}
+			c.buckets.Remove(b)
+		} else {
+			prev = b
+		}
+
+		b = next
+	}
 }
 
-func (l *lfuHeap) Pop() any {
-	old := *l
-	n := len(old)
-	item := old[n-1]
-	*l = old[0 : n-1]
-	return item
+// unlink removes an item from its bucket, dropping the bucket if it becomes empty.
+func (c *LFU) unlink(elem *list.Element) {
+	bucket := elem.Value.(*listItem).bucket
+	items := bucket.Value.(*frequencyBucket).items
+
+	items.Remove(elem)
+	if items.Len() == 0 {
+		c.buckets.Remove(bucket)
+	}
 }
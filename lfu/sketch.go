@@ -0,0 +1,128 @@
+package lfu
+
+import "hash/fnv"
+
+// sketchDepth is the number of rows (hash functions) of the count-min sketch.
+const sketchDepth = 4
+
+// countMinSketch estimates access frequencies in a fixed amount of memory.
+//
+// Each key increments one saturating counter per row, and its estimate is the
+// smallest of them, so collisions can only overestimate a frequency. Once the
+// number of increments reaches resetAt, every counter is halved, so keys that
+// were popular long ago gradually lose their weight.
+type countMinSketch struct {
+	rows    [sketchDepth][]uint8
+	mask    uint64
+	added   int
+	resetAt int
+}
+
+// sketchWidthFactor is the number of counters per row for each key the
+// sketch is sized for, keeping collisions rare.
+const sketchWidthFactor = 4
+
+// maxSketchWidth bounds the number of counters per row, 64 MiB for the whole
+// sketch, so a very large or unbounded maxSize neither overflows the width
+// computation nor allocates more than needed to keep collisions rare.
+const maxSketchWidth = 1 << 24
+
+// newCountMinSketch creates a sketch sized for about keys distinct keys.
+func newCountMinSketch(keys int) *countMinSketch {
+	size := 64
+	for size < maxSketchWidth && size/sketchWidthFactor < keys {
+		size <<= 1
+	}
+
+	s := &countMinSketch{
+		mask:    uint64(size - 1),
+		resetAt: size * 10,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, size)
+	}
+
+	return s
+}
+
+// hashes returns the two base hashes of a key, combined per row.
+func (s *countMinSketch) hashes(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+
+	return sum, sum>>32 | 1
+}
+
+// Increment records an access to key. It returns true if the counters were
+// halved, so that callers can age their own frequencies too.
+func (s *countMinSketch) Increment(key string) bool {
+	h1, h2 := s.hashes(key)
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		if s.rows[i][idx] < 255 {
+			s.rows[i][idx]++
+		}
+	}
+
+	s.added++
+	if s.added < s.resetAt {
+		return false
+	}
+
+	s.halve()
+	return true
+}
+
+// Estimate returns the estimated access frequency of key.
+func (s *countMinSketch) Estimate(key string) int {
+	h1, h2 := s.hashes(key)
+
+	estimate := 255
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		if v := int(s.rows[i][idx]); v < estimate {
+			estimate = v
+		}
+	}
+
+	return estimate
+}
+
+// halve divides every counter by two.
+func (s *countMinSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.added /= 2
+}
+
+// Seed raises the counters of key to at least count, without counting it as
+// an access towards the next halving. It carries the frequency of a stored
+// key over to a new sketch.
+func (s *countMinSketch) Seed(key string, count int) {
+	count = min(count, 255)
+
+	h1, h2 := s.hashes(key)
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		if int(s.rows[i][idx]) < count {
+			s.rows[i][idx] = uint8(count)
+		}
+	}
+}
+
+// Keys returns the number of distinct keys the sketch is sized for.
+func (s *countMinSketch) Keys() int {
+	return len(s.rows[0]) / sketchWidthFactor
+}
+
+// Reset clears every counter.
+func (s *countMinSketch) Reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.added = 0
+}
@@ -0,0 +1,45 @@
+package lfu
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the sketch width grows with the key count up to its bound
+func TestCountMinSketchWidth(t *testing.T) {
+	for keys, width := range map[int]int{
+		0:           64,
+		1:           64,
+		1000:        4096,
+		1 << 30:     maxSketchWidth,
+		math.MaxInt: maxSketchWidth,
+	} {
+		assert.Len(t, newCountMinSketch(keys).rows[0], width, "keys %d", keys)
+	}
+}
+
+// Test an unbounded Approx gets a full-sized sketch, grown by Reserve
+func TestApproxSketchSize(t *testing.T) {
+	bounded := NewApprox(10).(*Approx)
+	assert.Len(t, bounded.sketch.rows[0], 64)
+
+	c := NewApprox(0).(*Approx)
+	assert.Equal(t, unboundedSketchKeys, c.sketch.Keys())
+
+	c.Set("A", "Item A")
+	for i := 0; i < 5; i++ {
+		c.Get("A")
+	}
+	frequency := c.sketch.Estimate("A")
+
+	// Reserving fewer keys keeps the sketch, more grows it
+	c.Reserve(1000)
+	assert.Equal(t, unboundedSketchKeys, c.sketch.Keys())
+
+	c.Reserve(1 << 20)
+	assert.Equal(t, 1<<20, c.sketch.Keys())
+	assert.Equal(t, frequency, c.sketch.Estimate("A"))
+	assert.Equal(t, 0, c.sketch.Estimate("B"))
+}
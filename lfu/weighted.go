@@ -0,0 +1,474 @@
+package lfu
+
+import (
+	"container/heap"
+	"errors"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// ErrHeapCorrupted is reported to the corruption hook (see SetOnCorruption)
+// when an item's heap index no longer matches its position in the heap.
+var ErrHeapCorrupted = errors.New("lfu: heap index out of sync")
+
+// Weighted is a heap-based LFU cache where items can carry a weight (cost).
+//
+// Each item maintains a usage counter that increments every time the item is
+// accessed, and the eviction order is given by a score combining frequency and
+// weight (see SetWeighted), so costly items are retained longer.
+//
+// When several items share the lowest score, the least recently used among
+// them is evicted first, which keeps eviction deterministic.
+//
+// Frequencies saturate at a ceiling (see SetMaxFrequency); items at the
+// ceiling with the same weight are ordered by recency.
+//
+// If the heap is found corrupted, by an item whose index is out of sync or a
+// heap operation that panics, it is rebuilt from the stored items instead of
+// crashing, and the corruption is reported to the hook set by SetOnCorruption.
+//
+// Keeping the heap ordered makes every access O(log n); use LFU when weights
+// are not needed.
+type Weighted struct {
+	maxSize int
+	data    map[string]*cacheItem
+	lfuHeap *lfuHeap
+	lock    sync.RWMutex
+
+	// clock is a logical timestamp incremented on every access, used to
+	// break ties between items with the same score.
+	clock uint64
+
+	// maxFrequency is the ceiling at which frequencies stop increasing.
+	maxFrequency int
+
+	// window debounces the frequency increments (see SetCountWindow).
+	window countWindow
+
+	// onCorruption, if set, is called with the cause of every heap rebuild.
+	onCorruption func(recovered any)
+}
+
+var (
+	_ engine.Engine           = (*Weighted)(nil)
+	_ engine.Weighted         = (*Weighted)(nil)
+	_ engine.Reserver         = (*Weighted)(nil)
+	_ engine.SelectiveEvicter = (*Weighted)(nil)
+)
+
+type cacheItem struct {
+	key        string
+	value      any
+	frequency  int
+	weight     float64
+	lastAccess uint64
+	index      int
+
+	// countedAt is the time of the last access that incremented the
+	// frequency, with SetCountWindow.
+	countedAt time.Time
+}
+
+// ScoreFunc computes the eviction score of an item from its access frequency
+// and weight. Items with the lowest score are evicted first.
+type ScoreFunc func(frequency int, weight float64) float64
+
+// DefaultScore orders items by frequency multiplied by weight.
+func DefaultScore(frequency int, weight float64) float64 {
+	return float64(frequency) * weight
+}
+
+// NewWithScore creates a weighted LFU cache ordering items by the given score
+// function. If score is nil, DefaultScore is used.
+func NewWithScore(maxSize int, score ScoreFunc) engine.Engine {
+	if score == nil {
+		score = DefaultScore
+	}
+
+	l := &lfuHeap{score: score}
+	heap.Init(l)
+
+	return &Weighted{
+		maxSize:      maxSize,
+		data:         make(map[string]*cacheItem),
+		lfuHeap:      l,
+		maxFrequency: DefaultMaxFrequency,
+	}
+}
+
+// SetMaxFrequency sets the ceiling at which frequencies stop increasing. A
+// value of 0 or less restores DefaultMaxFrequency. It should be called before
+// the cache is used.
+func (c *Weighted) SetMaxFrequency(maxFrequency int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if maxFrequency <= 0 {
+		maxFrequency = DefaultMaxFrequency
+	}
+
+	c.maxFrequency = maxFrequency
+}
+
+// SetCountWindow makes accesses to an item within window of its last counted
+// access only refresh its recency, without incrementing its frequency. The
+// accesses are timed with now, or time.Now if nil. A window of 0 counts every
+// access. It should be called before the cache is used.
+func (c *Weighted) SetCountWindow(window time.Duration, now func() time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.window.set(window, now)
+}
+
+// SetOnCorruption sets a hook called with the cause of every heap rebuild:
+// ErrHeapCorrupted, or the value recovered from a panicking heap operation.
+// It is called with the engine lock held, so it must not use the engine.
+func (c *Weighted) SetOnCorruption(fn func(recovered any)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.onCorruption = fn
+}
+
+func (c *Weighted) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return nil, false
+	}
+
+	c.hit(item)
+	c.fix(item)
+
+	return item.value, true
+}
+
+func (c *Weighted) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	return item.value, time.Time{}, true
+}
+
+func (c *Weighted) Set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if item, exists := c.data[key]; exists {
+		item.value = value
+		c.hit(item)
+		c.fix(item)
+		return
+	}
+
+	c.push(&cacheItem{key: key, value: value, frequency: 1, weight: 1})
+}
+
+func (c *Weighted) SetWeighted(key string, value any, weight float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if item, exists := c.data[key]; exists {
+		item.value = value
+		item.weight = weight
+		c.hit(item)
+		c.fix(item)
+		return
+	}
+
+	c.push(&cacheItem{key: key, value: value, frequency: 1, weight: weight})
+}
+
+// push adds a new item to the heap. The caller must hold the lock.
+func (c *Weighted) push(item *cacheItem) {
+	item.countedAt = c.window.start()
+	item.lastAccess = c.tick()
+	c.data[item.key] = item
+	c.guard(nil, func() {
+		heap.Push(c.lfuHeap, item)
+	})
+}
+
+func (c *Weighted) SetWithTTL(key string, value any, expiresAt time.Time) {
+	c.Set(key, value)
+}
+
+func (c *Weighted) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return
+	}
+
+	delete(c.data, key)
+	c.guard(item, func() {
+		heap.Remove(c.lfuHeap, item.index)
+	})
+}
+
+func (c *Weighted) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, exists := c.data[key]
+	return exists
+}
+
+func (c *Weighted) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.data)
+}
+
+func (c *Weighted) IsExpirable() bool {
+	return false
+}
+
+func (c *Weighted) IsExpired(key string) bool {
+	return false
+}
+
+func (c *Weighted) Touch(key string, expiresAt time.Time) bool {
+	return false
+}
+
+func (c *Weighted) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.data) > 0 {
+		var item *cacheItem
+		c.guard(nil, func() {
+			item = heap.Pop(c.lfuHeap).(*cacheItem)
+		})
+
+		// An item that is no longer stored means the heap was out of sync
+		if item == nil || c.data[item.key] != item {
+			if item != nil {
+				c.rebuild(ErrHeapCorrupted)
+			}
+			continue
+		}
+
+		delete(c.data, item.key)
+		return item.key, item.value, true
+	}
+
+	return "", nil, false
+}
+
+// EvictFunc removes the item with the lowest score among the ones accepted
+// by accept. The skipped items are popped from the heap and pushed back, so
+// skipping k items costs O(k log n).
+func (c *Weighted) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var skipped []*cacheItem
+	defer func() {
+		for _, item := range skipped {
+			c.guard(nil, func() {
+				heap.Push(c.lfuHeap, item)
+			})
+		}
+	}()
+
+	for len(c.data) > len(skipped) {
+		var item *cacheItem
+		c.guard(nil, func() {
+			item = heap.Pop(c.lfuHeap).(*cacheItem)
+		})
+
+		// A rebuild puts the skipped items back in the heap
+		if item == nil || c.data[item.key] != item {
+			if item != nil {
+				c.rebuild(ErrHeapCorrupted)
+			}
+			skipped = skipped[:0]
+			continue
+		}
+
+		if !accept(item.key, item.value) {
+			skipped = append(skipped, item)
+			continue
+		}
+
+		delete(c.data, item.key)
+		return item.key, item.value, true
+	}
+
+	return "", nil, false
+}
+
+// hit counts an access to an item, saturating its frequency at the ceiling.
+func (c *Weighted) hit(item *cacheItem) {
+	if item.frequency < c.maxFrequency && c.window.counts(&item.countedAt) {
+		item.frequency++
+	}
+	item.lastAccess = c.tick()
+}
+
+// fix restores the position of an item in the heap after its score changed.
+// The caller must hold the lock.
+func (c *Weighted) fix(item *cacheItem) {
+	c.guard(item, func() {
+		heap.Fix(c.lfuHeap, item.index)
+	})
+}
+
+// guard runs a heap operation on item, or on the heap as a whole if item is
+// nil. If item's index is out of sync or the operation panics, the heap is
+// rebuilt from the stored items instead. The caller must hold the lock.
+func (c *Weighted) guard(item *cacheItem, op func()) {
+	if item != nil && !c.lfuHeap.holds(item) {
+		c.rebuild(ErrHeapCorrupted)
+		return
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			c.rebuild(recovered)
+		}
+	}()
+
+	op()
+}
+
+// rebuild rebuilds the heap from the stored items and reports the cause to
+// the corruption hook. The caller must hold the lock.
+func (c *Weighted) rebuild(cause any) {
+	items := make([]*cacheItem, 0, len(c.data))
+	for _, item := range c.data {
+		item.index = len(items)
+		items = append(items, item)
+	}
+	c.lfuHeap.items = items
+	heap.Init(c.lfuHeap)
+
+	if c.onCorruption != nil {
+		c.onCorruption(cause)
+	}
+}
+
+// tick advances the logical clock and returns the new time.
+func (c *Weighted) tick() uint64 {
+	c.clock++
+	return c.clock
+}
+
+func (c *Weighted) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	ordered := &lfuHeap{
+		items: make([]*cacheItem, len(c.lfuHeap.items)),
+		score: c.lfuHeap.score,
+	}
+	copy(ordered.items, c.lfuHeap.items)
+	sort.Slice(ordered.items, ordered.Less)
+
+	keys := make([]string, 0, len(ordered.items))
+	for _, item := range ordered.items {
+		keys = append(keys, item.key)
+	}
+
+	return keys
+}
+
+func (c *Weighted) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, item := range c.data {
+		if !fn(key, item.value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index and the heap to hold n items without
+// reallocating.
+func (c *Weighted) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*cacheItem, n)
+	maps.Copy(data, c.data)
+	c.data = data
+
+	items := make([]*cacheItem, len(c.lfuHeap.items), n)
+	copy(items, c.lfuHeap.items)
+	c.lfuHeap.items = items
+}
+
+func (c *Weighted) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*cacheItem)
+	c.lfuHeap.items = c.lfuHeap.items[:0]
+}
+
+type lfuHeap struct {
+	items []*cacheItem
+	score ScoreFunc
+}
+
+// holds reports whether item is at the position its index points to.
+func (l *lfuHeap) holds(item *cacheItem) bool {
+	return item.index >= 0 && item.index < len(l.items) && l.items[item.index] == item
+}
+
+func (l *lfuHeap) Len() int {
+	return len(l.items)
+}
+
+func (l *lfuHeap) Less(i, j int) bool {
+	a, b := l.items[i], l.items[j]
+	scoreA, scoreB := l.score(a.frequency, a.weight), l.score(b.frequency, b.weight)
+	if scoreA != scoreB {
+		return scoreA < scoreB
+	}
+
+	return a.lastAccess < b.lastAccess
+}
+
+func (l *lfuHeap) Swap(i, j int) {
+	l.items[i], l.items[j] = l.items[j], l.items[i]
+	l.items[i].index = i
+	l.items[j].index = j
+}
+
+func (l *lfuHeap) Push(x any) {
+	n := len(l.items)
+	item := x.(*cacheItem)
+	item.index = n
+	l.items = append(l.items, item)
+}
+
+func (l *lfuHeap) Pop() any {
+	old := l.items
+	n := len(old)
+	item := old[n-1]
+	l.items = old[0 : n-1]
+	return item
+}
@@ -0,0 +1,46 @@
+package lfu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test a corrupted heap index is detected and the heap rebuilt from the stored items
+func TestWeightedRecoversCorruptedHeap(t *testing.T) {
+	c := NewWithScore(0, nil).(*Weighted)
+
+	var causes []any
+	c.SetOnCorruption(func(recovered any) {
+		causes = append(causes, recovered)
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+	c.Get("B")
+	c.Get("C")
+	c.Get("C")
+
+	// Point A at B's slot, then out of the heap
+	c.data["A"].index = c.data["B"].index
+	assert.NotPanics(t, func() { c.Get("A") })
+	c.data["B"].index = 42
+	assert.NotPanics(t, func() { c.Delete("B") })
+
+	assert.Equal(t, []any{ErrHeapCorrupted, ErrHeapCorrupted}, causes)
+	assert.False(t, c.Has("B"))
+	assert.Equal(t, []string{"A", "C"}, c.Keys())
+
+	key, _, evicted := c.Evict()
+	assert.True(t, evicted)
+	assert.Equal(t, "A", key)
+	assert.Equal(t, 1, c.Len())
+
+	// A heap emptied behind the engine's back is rebuilt on eviction
+	c.lfuHeap.items = c.lfuHeap.items[:0]
+	key, _, evicted = c.Evict()
+	assert.True(t, evicted)
+	assert.Equal(t, "C", key)
+	assert.Len(t, causes, 3)
+}
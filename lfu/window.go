@@ -0,0 +1,47 @@
+package lfu
+
+import "time"
+
+// countWindow debounces frequency increments: repeated accesses to an item
+// within window count once, so a burst of accesses doesn't permanently
+// inflate its frequency. The zero value counts every access.
+type countWindow struct {
+	window time.Duration
+	now    func() time.Time
+}
+
+// start returns the time to record as the last counted access of a new item,
+// so accesses within the window of its insertion don't count.
+func (w *countWindow) start() time.Time {
+	if w.window <= 0 {
+		return time.Time{}
+	}
+
+	return w.now()
+}
+
+// counts reports whether an access to an item whose last counted access was
+// at countedAt increments its frequency, and records it if so.
+func (w *countWindow) counts(countedAt *time.Time) bool {
+	if w.window <= 0 {
+		return true
+	}
+
+	now := w.now()
+	if !countedAt.IsZero() && now.Sub(*countedAt) < w.window {
+		return false
+	}
+
+	*countedAt = now
+	return true
+}
+
+// set configures the window, using time.Now if now is nil.
+func (w *countWindow) set(window time.Duration, now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+
+	w.window = window
+	w.now = now
+}
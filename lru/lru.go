@@ -2,6 +2,7 @@ package lru
 
 import (
 	"container/list"
+	"maps"
 	"sync"
 	"time"
 
@@ -22,11 +23,47 @@ type LRU struct {
 	data         map[string]*list.Element
 	evictionList *list.List
 	lock         sync.RWMutex
+
+	// clock times the accesses reported by LastAccess (see SetClock).
+	clock func() time.Time
 }
 
+var (
+	_ engine.Engine           = (*LRU)(nil)
+	_ engine.Reserver         = (*LRU)(nil)
+	_ engine.AccessTracker    = (*LRU)(nil)
+	_ engine.SelectiveEvicter = (*LRU)(nil)
+)
+
 type cacheItem struct {
 	key   string
 	value any
+
+	// lastAccess is the time of the last Get, or the zero time.
+	lastAccess time.Time
+}
+
+// itemPool recycles cacheItem structs released on Delete and Evict,
+// reducing allocations under high churn.
+var itemPool = sync.Pool{
+	New: func() any {
+		return new(cacheItem)
+	},
+}
+
+// newItem returns a cacheItem from the pool.
+func newItem(key string, value any) *cacheItem {
+	item := itemPool.Get().(*cacheItem)
+	item.key = key
+	item.value = value
+	return item
+}
+
+// releaseItem resets an item and returns it to the pool. The item must no
+// longer be referenced by the cache.
+func releaseItem(item *cacheItem) {
+	*item = cacheItem{}
+	itemPool.Put(item)
 }
 
 func New(maxSize int) engine.Engine {
@@ -37,6 +74,24 @@ func New(maxSize int) engine.Engine {
 	}
 }
 
+// SetClock makes the access times reported by LastAccess come from now
+// instead of time.Now. It should be called before the cache is used.
+func (c *LRU) SetClock(now func() time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.clock = now
+}
+
+// now returns the current time from the configured clock.
+func (c *LRU) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	return time.Now()
+}
+
 func (c *LRU) Get(key string) (any, bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -47,11 +102,39 @@ func (c *LRU) Get(key string) (any, bool) {
 	}
 
 	c.evictionList.MoveToFront(elem)
-	value := elem.Value.(*cacheItem).value
+	item := elem.Value.(*cacheItem)
+	item.lastAccess = c.now()
+	value := item.value
 
 	return value, true
 }
 
+func (c *LRU) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	return elem.Value.(*cacheItem).value, time.Time{}, true
+}
+
+// LastAccess returns the time the item was last read with Get, or the zero
+// time if it was never read. It returns false if the key is missing.
+func (c *LRU) LastAccess(key string) (time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	elem, exists := c.data[key]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	return elem.Value.(*cacheItem).lastAccess, true
+}
+
 func (c *LRU) Set(key string, value any) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -62,7 +145,7 @@ func (c *LRU) Set(key string, value any) {
 		return
 	}
 
-	item := &cacheItem{key: key, value: value}
+	item := newItem(key, value)
 	elem := c.evictionList.PushFront(item)
 	c.data[key] = elem
 }
@@ -82,6 +165,7 @@ func (c *LRU) Delete(key string) {
 
 	delete(c.data, key)
 	c.evictionList.Remove(elem)
+	releaseItem(elem.Value.(*cacheItem))
 }
 
 func (c *LRU) Has(key string) bool {
@@ -99,20 +183,51 @@ func (c *LRU) Len() int {
 	return len(c.data)
 }
 
-func (c *LRU) Evict() {
+func (c *LRU) Evict() (string, any, bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	if len(c.data) == 0 {
-		return
+		return "", nil, false
 	}
 
 	elem := c.evictionList.Back()
-	if elem != nil {
+	if elem == nil {
+		return "", nil, false
+	}
+
+	item := elem.Value.(*cacheItem)
+	delete(c.data, item.key)
+	c.evictionList.Remove(elem)
+
+	key, value := item.key, item.value
+	releaseItem(item)
+
+	return key, value, true
+}
+
+// EvictFunc removes the least recently used item among the ones accepted by
+// accept.
+func (c *LRU) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for elem := c.evictionList.Back(); elem != nil; elem = elem.Prev() {
 		item := elem.Value.(*cacheItem)
+		if !accept(item.key, item.value) {
+			continue
+		}
+
 		delete(c.data, item.key)
 		c.evictionList.Remove(elem)
+
+		key, value := item.key, item.value
+		releaseItem(item)
+
+		return key, value, true
 	}
+
+	return "", nil, false
 }
 
 func (c *LRU) IsExpirable() bool {
@@ -122,3 +237,52 @@ func (c *LRU) IsExpirable() bool {
 func (c *LRU) IsExpired(key string) bool {
 	return false
 }
+
+func (c *LRU) Touch(key string, expiresAt time.Time) bool {
+	return false
+}
+
+func (c *LRU) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]string, 0, len(c.data))
+	for elem := c.evictionList.Back(); elem != nil; elem = elem.Prev() {
+		keys = append(keys, elem.Value.(*cacheItem).key)
+	}
+
+	return keys
+}
+
+func (c *LRU) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, elem := range c.data {
+		if !fn(key, elem.Value.(*cacheItem).value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index to hold n items without rehashing.
+func (c *LRU) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*list.Element, n)
+	maps.Copy(data, c.data)
+	c.data = data
+}
+
+func (c *LRU) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*list.Element)
+	c.evictionList.Init()
+}
@@ -0,0 +1,328 @@
+package lruk
+
+import (
+	"container/heap"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// DefaultK is the number of references tracked per item when K is not set.
+const DefaultK = 2
+
+// LRUK (Least Recently Used, K references) is a cache implementation that removes
+// the item whose K-th most recent access is the oldest.
+//
+// Each item keeps a bounded history of its last K access times. Items accessed
+// fewer than K times are considered to have an infinitely old K-th reference, so
+// they are evicted first (the least recently used among them goes first).
+//
+// Unlike plain LRU, a single sequential scan over many keys does not push
+// frequently accessed items out of the cache, since scanned keys are only
+// referenced once.
+//
+// The items are also kept in a heap ordered by their K-th reference, fixed on
+// every access, so that eviction costs O(log n).
+type LRUK struct {
+	maxSize int
+	k       int
+	data    map[string]*cacheItem
+	queue   byAge
+	lock    sync.RWMutex
+
+	// clock is a logical timestamp incremented on every access. It is used
+	// instead of wall-clock time so that accesses are strictly ordered.
+	clock uint64
+}
+
+var (
+	_ engine.Engine           = (*LRUK)(nil)
+	_ engine.Reserver         = (*LRUK)(nil)
+	_ engine.SelectiveEvicter = (*LRUK)(nil)
+)
+
+type cacheItem struct {
+	key   string
+	value any
+
+	// history holds the last K access times, used as a ring buffer.
+	history []uint64
+	next    int
+	count   int
+
+	// index is the position of the item in the eviction queue.
+	index int
+}
+
+func New(maxSize, k int) engine.Engine {
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	c := &LRUK{
+		maxSize: maxSize,
+		k:       k,
+		data:    make(map[string]*cacheItem),
+	}
+	c.queue.cache = c
+
+	return c
+}
+
+func (c *LRUK) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return nil, false
+	}
+
+	c.touch(item)
+	heap.Fix(&c.queue, item.index)
+
+	return item.value, true
+}
+
+func (c *LRUK) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	return item.value, time.Time{}, true
+}
+
+func (c *LRUK) Set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if item, exists := c.data[key]; exists {
+		item.value = value
+		c.touch(item)
+		heap.Fix(&c.queue, item.index)
+		return
+	}
+
+	item := &cacheItem{key: key, value: value, history: make([]uint64, c.k)}
+	c.touch(item)
+	c.data[key] = item
+	heap.Push(&c.queue, item)
+}
+
+func (c *LRUK) SetWithTTL(key string, value any, expiresAt time.Time) {
+	c.Set(key, value)
+}
+
+func (c *LRUK) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, exists := c.data[key]
+	if !exists {
+		return
+	}
+
+	delete(c.data, key)
+	heap.Remove(&c.queue, item.index)
+}
+
+func (c *LRUK) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, exists := c.data[key]
+	return exists
+}
+
+func (c *LRUK) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.data)
+}
+
+func (c *LRUK) IsExpirable() bool {
+	return false
+}
+
+func (c *LRUK) IsExpired(key string) bool {
+	return false
+}
+
+func (c *LRUK) Touch(key string, expiresAt time.Time) bool {
+	return false
+}
+
+// Evict removes the item with the oldest K-th most recent access, taken from
+// the top of the eviction queue in O(log n).
+func (c *LRUK) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.queue.Len() == 0 {
+		return "", nil, false
+	}
+
+	victim := heap.Pop(&c.queue).(*cacheItem)
+	delete(c.data, victim.key)
+
+	return victim.key, victim.value, true
+}
+
+// EvictFunc removes the item with the oldest K-th reference among the ones
+// accepted by accept. The rejected items are popped from the eviction queue
+// and pushed back afterwards, so skipping k items costs O(k log n).
+func (c *LRUK) EvictFunc(accept func(key string, value any) bool) (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var rejected []*cacheItem
+	defer func() {
+		for _, item := range rejected {
+			heap.Push(&c.queue, item)
+		}
+	}()
+
+	for c.queue.Len() > 0 {
+		victim := heap.Pop(&c.queue).(*cacheItem)
+		if !accept(victim.key, victim.value) {
+			rejected = append(rejected, victim)
+			continue
+		}
+
+		delete(c.data, victim.key)
+		return victim.key, victim.value, true
+	}
+
+	return "", nil, false
+}
+
+// byAge is a heap of items ordered by older.
+type byAge struct {
+	cache *LRUK
+	items []*cacheItem
+}
+
+func (h *byAge) Len() int           { return len(h.items) }
+func (h *byAge) Less(i, j int) bool { return h.cache.older(h.items[i], h.items[j]) }
+
+func (h *byAge) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *byAge) Push(x any) {
+	item := x.(*cacheItem)
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *byAge) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return item
+}
+
+func (c *LRUK) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	items := make([]*cacheItem, 0, len(c.data))
+	for _, item := range c.data {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return c.older(items[i], items[j])
+	})
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.key)
+	}
+
+	return keys
+}
+
+func (c *LRUK) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, item := range c.data {
+		if !fn(key, item.value) {
+			return
+		}
+	}
+}
+
+// Reserve grows the key index to hold n items without rehashing.
+func (c *LRUK) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if n <= len(c.data) {
+		return
+	}
+
+	data := make(map[string]*cacheItem, n)
+	maps.Copy(data, c.data)
+	c.data = data
+
+	items := make([]*cacheItem, len(c.queue.items), n)
+	copy(items, c.queue.items)
+	c.queue.items = items
+}
+
+func (c *LRUK) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.data = make(map[string]*cacheItem)
+	c.queue.items = nil
+}
+
+// touch records a new access on the item.
+func (c *LRUK) touch(item *cacheItem) {
+	c.clock++
+	item.history[item.next] = c.clock
+	item.next = (item.next + 1) % c.k
+	if item.count < c.k {
+		item.count++
+	}
+}
+
+// older reports whether a should be evicted before b.
+//
+// Items with fewer than K references come first; among them, and among items
+// with K references, the one with the oldest reference loses.
+func (c *LRUK) older(a, b *cacheItem) bool {
+	aFull, bFull := a.count == c.k, b.count == c.k
+	if aFull != bFull {
+		return !aFull
+	}
+
+	if !aFull {
+		return last(a, c.k) < last(b, c.k)
+	}
+
+	return kth(a) < kth(b)
+}
+
+// last returns the most recent access time of the item.
+func last(item *cacheItem, k int) uint64 {
+	return item.history[(item.next-1+k)%k]
+}
+
+// kth returns the K-th most recent access time of an item with K references.
+func kth(item *cacheItem) uint64 {
+	return item.history[item.next]
+}
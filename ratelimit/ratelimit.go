@@ -0,0 +1,76 @@
+// Package ratelimit implements fixed-window rate limiting on top of a cache.
+//
+// Every key gets a counter per window, stored in the cache until the end of
+// the window:
+//
+//	limiter := ratelimit.New(cache.New(&cache.Config{EvictionPolicy: cache.Basic}))
+//
+//	if !limiter.Allow(clientIP, 100, time.Minute) {
+//		http.Error(w, "too many requests", http.StatusTooManyRequests)
+//		return
+//	}
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+)
+
+// Options defines the settings of a Limiter.
+type Options struct {
+	// Clock returns the current time used to find the current window. It
+	// should match the Clock of the cache. If nil, time.Now is used.
+	Clock func() time.Time
+}
+
+// Limiter counts the requests made for each key in fixed time windows.
+//
+// Counters are updated with Cache.Increment, so concurrent requests, even
+// through several Limiters sharing the cache, are never lost. With an
+// expirable policy (Basic or FIFO) they expire at the end of their window.
+// With other policies they are only reclaimed by eviction, but a new window
+// always starts a new counter.
+type Limiter struct {
+	cache *cache.Cache
+	clock func() time.Time
+}
+
+// New creates a Limiter storing its counters in c.
+func New(c *cache.Cache) *Limiter {
+	return NewWithOptions(c, Options{})
+}
+
+// NewWithOptions creates a Limiter storing its counters in c, with the given options.
+func NewWithOptions(c *cache.Cache, opts Options) *Limiter {
+	clock := opts.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Limiter{cache: c, clock: clock}
+}
+
+// Allow counts a request for key and reports whether it is within limit for
+// the current window.
+//
+// Windows are aligned on multiples of window, so all keys reset at the same
+// time. Denied requests are not counted. It returns false if limit or window
+// is not positive.
+func (l *Limiter) Allow(key string, limit int, window time.Duration) bool {
+	if limit <= 0 || window <= 0 {
+		return false
+	}
+
+	start := l.clock().Truncate(window)
+	counter := cache.CompositeKey(key, strconv.FormatInt(start.UnixNano(), 10))
+
+	if l.cache.Increment(counter, 1, start.Add(window)) > limit {
+		// Take the denied request back out of the count
+		l.cache.Increment(counter, -1, start.Add(window))
+		return false
+	}
+
+	return true
+}
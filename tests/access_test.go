@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `LastAccess()` advances on reads without changing the eviction order
+func TestLastAccess(t *testing.T) {
+	configs := map[string]*cache.Config{
+		"lru":        {EvictionPolicy: cache.LRU, MaxSize: 10},
+		"lfu":        {EvictionPolicy: cache.LFU, MaxSize: 10},
+		"lfu-approx": {EvictionPolicy: cache.LFU, MaxSize: 10, LFUApproxCounters: true},
+	}
+
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			c := cache.New(cfg)
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			c.Set("B", "Item B")
+
+			at, found := c.LastAccess("A")
+			assert.True(t, found)
+			assert.True(t, at.IsZero())
+
+			before := time.Now()
+			c.Get("A")
+			first, _ := c.LastAccess("A")
+			assert.False(t, first.Before(before))
+
+			time.Sleep(2 * time.Millisecond)
+			c.Get("A")
+			second, _ := c.LastAccess("A")
+			assert.True(t, second.After(first))
+
+			// Reading the access time is not an access
+			order := c.EvictionOrder()
+			c.LastAccess("B")
+			assert.Equal(t, order, c.EvictionOrder())
+
+			_, found = c.LastAccess("missing")
+			assert.False(t, found)
+		})
+	}
+}
+
+// Test `LastAccess()` times the reads with `Config.Clock`
+func TestLastAccessClock(t *testing.T) {
+	for _, approx := range []bool{false, true} {
+		for _, policy := range []cache.EvictionPolicy{cache.LRU, cache.LFU} {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:    policy,
+				MaxSize:           10,
+				LFUApproxCounters: approx,
+				Clock:             clock.Now,
+			})
+
+			c.Set("A", "Item A")
+			clock.Advance(time.Hour)
+			c.Get("A")
+
+			at, found := c.LastAccess("A")
+			assert.True(t, found)
+			assert.Equal(t, clock.Now(), at, "%s, approx %v", policy, approx)
+		}
+	}
+}
+
+// Test `LastAccess()` reports false for policies that don't record accesses
+func TestLastAccessUnsupported(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 10})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	c.Get("A")
+
+	_, found := c.LastAccess("A")
+	assert.False(t, found)
+}
@@ -2,10 +2,19 @@ package tests
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/hugocarreira/easycache/basic"
 	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/hugocarreira/easycache/fifo"
+	"github.com/hugocarreira/easycache/lfu"
+	"github.com/hugocarreira/easycache/lru"
+	"github.com/hugocarreira/easycache/lruk"
 )
 
 var testCache *cache.Cache
@@ -107,3 +116,300 @@ func BenchmarkLFUEviction(b *testing.B) {
 		}
 	}
 }
+
+// benchmarkLFUGet measures Get on an LFU engine under a high access rate
+func benchmarkLFUGet(b *testing.B, e engine.Engine) {
+	const size = 100000
+
+	keys := make([]string, size)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		e.Set(keys[i], "value")
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Get(keys[i%size])
+	}
+}
+
+// BenchmarkLFUGetList (O(1) frequency lists)
+func BenchmarkLFUGetList(b *testing.B) {
+	benchmarkLFUGet(b, lfu.New(0))
+}
+
+// BenchmarkLFUGetHeap (O(log n) heap)
+func BenchmarkLFUGetHeap(b *testing.B) {
+	benchmarkLFUGet(b, lfu.NewWithScore(0, nil))
+}
+
+// benchmarkSetDeleteCycle measures a Set followed by a Delete on an engine
+func benchmarkSetDeleteCycle(b *testing.B, e engine.Engine) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Set("cycle-key", "value")
+		e.Delete("cycle-key")
+	}
+}
+
+// BenchmarkSetDeleteCycle (pooled cache items)
+func BenchmarkSetDeleteCycle(b *testing.B) {
+	b.Run("fifo", func(b *testing.B) {
+		benchmarkSetDeleteCycle(b, fifo.New(0))
+	})
+	b.Run("lru", func(b *testing.B) {
+		benchmarkSetDeleteCycle(b, lru.New(0))
+	})
+	b.Run("basic", func(b *testing.B) {
+		benchmarkSetDeleteCycle(b, basic.New(0, time.Minute, time.Minute))
+	})
+}
+
+// newLargeBasic returns a Basic engine holding n items
+func newLargeBasic(n int) *basic.Basic {
+	e := basic.New(0, time.Minute, time.Minute).(*basic.Basic)
+	for i := 0; i < n; i++ {
+		e.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+	return e
+}
+
+// BenchmarkBasicLen (O(1) while no item has expired)
+func BenchmarkBasicLen(b *testing.B) {
+	e := newLargeBasic(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Len()
+	}
+}
+
+// BenchmarkBasicLenExact (walks every item, O(n))
+func BenchmarkBasicLenExact(b *testing.B) {
+	e := newLargeBasic(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.LenExact()
+	}
+}
+
+// BenchmarkBasicEvict (a full cache evicting on every insertion, O(log n))
+func BenchmarkBasicEvict(b *testing.B) {
+	e := newLargeBasic(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Evict()
+		e.Set(fmt.Sprintf("new-%d", i), "value")
+	}
+}
+
+// BenchmarkLRUKEvict (a full cache evicting on every insertion, O(log n))
+func BenchmarkLRUKEvict(b *testing.B) {
+	e := lruk.New(0, lruk.DefaultK)
+	for i := 0; i < 100000; i++ {
+		e.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Evict()
+		e.Set(fmt.Sprintf("new-%d", i), "value")
+	}
+}
+
+// BenchmarkLenExpiring (steady TTL traffic: an item expires before every Len)
+func BenchmarkLenExpiring(b *testing.B) {
+	const n = 100000
+
+	engines := map[string]func(clock func() time.Time) engine.Engine{
+		"basic": func(clock func() time.Time) engine.Engine {
+			return basic.NewWithOptions(basic.Options{CleanupInterval: time.Hour, Clock: clock})
+		},
+		"fifo": func(clock func() time.Time) engine.Engine {
+			return fifo.NewWithOptions(fifo.Options{Clock: clock})
+		},
+	}
+
+	for name, newEngine := range engines {
+		b.Run(name, func(b *testing.B) {
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			e := newEngine(func() time.Time { return now })
+			if closer, ok := e.(engine.Closer); ok {
+				defer closer.Close()
+			}
+
+			for i := 0; i < n; i++ {
+				e.SetWithTTL(fmt.Sprintf("key-%d", i), "value", now.Add(time.Duration(i)*time.Millisecond))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				now = now.Add(time.Millisecond)
+				e.SetWithTTL(fmt.Sprintf("new-%d", i), "value", now.Add(n*time.Millisecond))
+				e.Len()
+			}
+		})
+	}
+}
+
+// benchmarkFill measures filling a cache with n keys, with an optional capacity hint
+func benchmarkFill(b *testing.B, n, initialCapacity int) {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := cache.New(&cache.Config{
+			EvictionPolicy:  cache.LRU,
+			InitialCapacity: initialCapacity,
+		})
+		for _, key := range keys {
+			c.Set(key, "value")
+		}
+	}
+}
+
+// BenchmarkFillInitialCapacity (preallocated key index vs growing from empty)
+func BenchmarkFillInitialCapacity(b *testing.B) {
+	const n = 100000
+
+	b.Run("without hint", func(b *testing.B) {
+		benchmarkFill(b, n, 0)
+	})
+	b.Run("with hint", func(b *testing.B) {
+		benchmarkFill(b, n, n)
+	})
+}
+
+// parallelKeySets are the key distributions of the parallel benchmarks: every
+// goroutine on the same key, or spread over many keys
+var parallelKeySets = []struct {
+	name string
+	keys int
+}{
+	{"contended", 1},
+	{"distributed", 10000},
+}
+
+// benchmarkParallel runs op from parallel goroutines on a cache of every
+// policy, prefilled with each key set. Goroutines start at different keys so
+// the distributed variant doesn't walk the keys in lockstep.
+func benchmarkParallel(b *testing.B, op func(c *cache.Cache, key string, i int)) {
+	for _, policy := range allPolicies {
+		for _, set := range parallelKeySets {
+			n := set.keys
+			b.Run(fmt.Sprintf("%s/%s", policy, set.name), func(b *testing.B) {
+				c := cache.New(&cache.Config{
+					EvictionPolicy: policy,
+					MaxSize:        2 * n,
+					TTL:            time.Minute,
+				})
+				defer c.Close()
+
+				keys := make([]string, n)
+				for i := range keys {
+					keys[i] = fmt.Sprintf("key-%d", i)
+					c.Set(keys[i], "value")
+				}
+
+				var goroutines atomic.Int64
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := int(goroutines.Add(1)) * 7919
+					for pb.Next() {
+						op(c, keys[i%n], i)
+						i++
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkParallelGet (lock contention of reads, such as LRU's write lock on Get)
+func BenchmarkParallelGet(b *testing.B) {
+	benchmarkParallel(b, func(c *cache.Cache, key string, _ int) {
+		c.Get(key)
+	})
+}
+
+// BenchmarkParallelSet (lock contention of writes to existing keys)
+func BenchmarkParallelSet(b *testing.B) {
+	benchmarkParallel(b, func(c *cache.Cache, key string, _ int) {
+		c.Set(key, "value")
+	})
+}
+
+// BenchmarkParallelMixed (90% reads, 10% writes)
+func BenchmarkParallelMixed(b *testing.B) {
+	benchmarkParallel(b, func(c *cache.Cache, key string, i int) {
+		if i%10 == 0 {
+			c.Set(key, "value")
+		} else {
+			c.Get(key)
+		}
+	})
+}
+
+// BenchmarkParallelMetrics (metric increments from parallel Get hits and misses)
+func BenchmarkParallelMetrics(b *testing.B) {
+	m := cache.NewMetrics()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%10 == 0 {
+				m.IncrementMisses()
+			} else {
+				m.IncrementHits()
+			}
+		}
+	})
+}
+
+// benchmarkRetainedKeys measures the heap kept alive by a cache re-setting the
+// same keys, each time sliced out of a freshly read 1 KB payload
+func benchmarkRetainedKeys(b *testing.B, intern bool) {
+	const keys, rounds = 1000, 10
+
+	var retained uint64
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		c := cache.New(&cache.Config{
+			EvictionPolicy: cache.LRU,
+			MaxSize:        keys,
+			InternKeys:     intern,
+		})
+		for r := 0; r < rounds; r++ {
+			for k := 0; k < keys; k++ {
+				payload := fmt.Sprintf("key-%06d|%s", k, strings.Repeat("x", 1024))
+				c.Set(payload[:10], r)
+			}
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(c)
+		if after.HeapAlloc > before.HeapAlloc {
+			retained += after.HeapAlloc - before.HeapAlloc
+		}
+	}
+
+	b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+}
+
+// BenchmarkInternKeys (keys sliced from large payloads, with and without interning)
+func BenchmarkInternKeys(b *testing.B) {
+	b.Run("plain", func(b *testing.B) {
+		benchmarkRetainedKeys(b, false)
+	})
+	b.Run("interned", func(b *testing.B) {
+		benchmarkRetainedKeys(b, true)
+	})
+}
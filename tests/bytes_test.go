@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `SetBytes()` and `GetBytes()` round-trip byte slices
+func TestSetBytes(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10})
+			defer c.Close()
+
+			c.SetBytes("body", []byte("hello"))
+			c.SetBytes("empty", []byte{})
+
+			b, found := c.GetBytes("body")
+			assert.True(t, found)
+			assert.Equal(t, []byte("hello"), b)
+
+			b, found = c.GetBytes("empty")
+			assert.True(t, found)
+			assert.Empty(t, b)
+
+			_, found = c.GetBytes("missing")
+			assert.False(t, found)
+
+			// Values that are not byte slices are not returned
+			c.Set("text", "hello")
+			_, found = c.GetBytes("text")
+			assert.False(t, found)
+		})
+	}
+}
+
+// Test `SetBytes()` with compression returns the original bytes
+func TestSetBytesCompressed(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10, Compress: true, CompressMinBytes: 16})
+
+	body := bytes.Repeat([]byte("payload "), 100)
+	c.SetBytes("body", body)
+
+	b, found := c.GetBytes("body")
+	assert.True(t, found)
+	assert.Equal(t, body, b)
+}
+
+// Test `SetBytes()` sizes values by their length instead of calling the `Sizer`
+func TestSetBytesSize(t *testing.T) {
+	calls := 0
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		MaxValueBytes:  8,
+		EstimateSize:   true,
+		Sizer: func(value any) int {
+			calls++
+			return 1
+		},
+	})
+
+	c.SetBytes("A", []byte("12345"))
+	c.SetBytes("B", []byte("123"))
+	assert.Equal(t, uint64(1+5+1+3), c.EstimatedBytes())
+
+	// Replacing a value accounts for the new length only
+	c.SetBytes("A", []byte("12"))
+	assert.Equal(t, uint64(1+2+1+3), c.EstimatedBytes())
+
+	// MaxValueBytes applies to the length
+	c.SetBytes("C", []byte("123456789"))
+	assert.False(t, c.Has("C"))
+	assert.Equal(t, uint64(1), c.RejectedSets())
+
+	assert.Equal(t, 0, calls)
+
+	c.Delete("A")
+	assert.Equal(t, uint64(1+3), c.EstimatedBytes())
+}
@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -66,7 +68,233 @@ func (suite *CacheTestSuite) TestLen() {
 	assert.Equal(suite.T(), 2, suite.c.Len())
 }
 
+// Test `Preload()`
+func (suite *CacheTestSuite) TestPreload() {
+	suite.c.Preload(map[string]any{"A": "Item A", "B": "Item B"})
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+	assert.Equal(suite.T(), 2, suite.c.Len())
+}
+
+// Test `Preload()` with more items than MaxSize
+func (suite *CacheTestSuite) TestPreloadOverMaxSize() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        3,
+		Metrics:        true,
+	})
+	events := c.Events()
+
+	var expired atomic.Int32
+	c.SetWithExpireCallback("Z", "Item Z", 0, func(any) { expired.Add(1) })
+	c.Preload(map[string]any{
+		"A": "Item A",
+		"B": "Item B",
+		"C": "Item C",
+		"D": "Item D",
+		"E": "Item E",
+	})
+
+	assert.Equal(suite.T(), 3, c.Len())
+	assert.Equal(suite.T(), int64(1), c.Metrics().Hits())
+	assert.Equal(suite.T(), int64(0), c.Metrics().Misses())
+
+	// The entries pushed out by Preload are evicted silently
+	assert.False(suite.T(), c.Has("Z"))
+	assert.Equal(suite.T(), int64(0), c.Metrics().Evictions())
+	assert.Equal(suite.T(), int64(0), c.Metrics().EvictionsPerMinute())
+	assert.Equal(suite.T(), int32(0), expired.Load())
+	assert.Len(suite.T(), events, 0)
+}
+
+// Test `SetIfAbsent()`
+func (suite *CacheTestSuite) TestSetIfAbsent() {
+	assert.True(suite.T(), suite.c.SetIfAbsent("A", "Item A"))
+	assert.False(suite.T(), suite.c.SetIfAbsent("A", "Other A"))
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+}
+
+// Test concurrent `SetIfAbsent()` on the same key
+func (suite *CacheTestSuite) TestSetIfAbsentConcurrent() {
+	var stored atomic.Int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if suite.c.SetIfAbsent("lock", i) {
+				stored.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(1), stored.Load())
+	assert.True(suite.T(), suite.c.Has("lock"))
+}
+
+// Test `LoadOrStore()`
+func (suite *CacheTestSuite) TestLoadOrStore() {
+	actual, loaded := suite.c.LoadOrStore("A", "Item A")
+	assert.False(suite.T(), loaded)
+	assert.Equal(suite.T(), "Item A", actual)
+
+	actual, loaded = suite.c.LoadOrStore("A", "Other A")
+	assert.True(suite.T(), loaded)
+	assert.Equal(suite.T(), "Item A", actual)
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+}
+
+// Test concurrent `LoadOrStore()` on the same key
+func (suite *CacheTestSuite) TestLoadOrStoreConcurrent() {
+	var stored atomic.Int32
+	var wg sync.WaitGroup
+	results := make([]any, 50)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, loaded := suite.c.LoadOrStore("key", i)
+			if !loaded {
+				stored.Add(1)
+			}
+			results[i] = actual
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(1), stored.Load())
+	for _, actual := range results {
+		assert.Equal(suite.T(), results[0], actual)
+	}
+}
+
+// Test `OnHit` and `OnMiss` callbacks
+func (suite *CacheTestSuite) TestHitMissCallbacks() {
+	var hits, misses []string
+
+	var c *cache.Cache
+	c = cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        3,
+		OnHit: func(key string) {
+			hits = append(hits, key)
+		},
+		OnMiss: func(key string) {
+			misses = append(misses, key)
+			// Callbacks run outside the lock and may use the cache
+			c.Set(key, "loaded")
+		},
+	})
+
+	c.Set("A", "Item A")
+	c.Get("A")
+	c.Get("B")
+	c.Get("B")
+
+	assert.Equal(suite.T(), []string{"A", "B"}, hits)
+	assert.Equal(suite.T(), []string{"B"}, misses)
+}
+
 // Run the test suite
 func TestCacheTestSuite(t *testing.T) {
 	suite.Run(t, new(CacheTestSuite))
 }
+
+// Test a panicking callback is recovered with `OnCallbackPanic`
+func TestOnCallbackPanic(t *testing.T) {
+	var recovered []any
+	var lock sync.Mutex
+
+	c := cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             10,
+		MemoryLimits:        1,
+		MemoryCheckInterval: 10 * time.Millisecond,
+		MemoryUsage:         func() uint64 { panic("memory usage") },
+		OnHit:               func(key string) { panic("hit " + key) },
+		OnCallbackPanic: func(r any) {
+			lock.Lock()
+			defer lock.Unlock()
+			recovered = append(recovered, r)
+		},
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	val, found := c.Get("A")
+	assert.True(t, found)
+	assert.Equal(t, "Item A", val)
+
+	// The background memory check survives its panicking callback
+	time.Sleep(50 * time.Millisecond)
+	c.Set("B", "Item B")
+	assert.True(t, c.Has("B"))
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Contains(t, recovered, "hit A")
+	assert.Contains(t, recovered, "memory usage")
+}
+
+// Test setting an unchanged value with `SkipIfEqual` leaves the eviction order untouched
+func TestSkipIfEqual(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.LRU, cache.LFU} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        2,
+				SkipIfEqual:    true,
+			})
+
+			c.Set("A", []int{1, 2})
+			c.Set("B", []int{3})
+			order := c.EvictionOrder()
+			assert.Equal(t, "A", order[0])
+
+			c.Set("A", []int{1, 2})
+			assert.Equal(t, order, c.EvictionOrder())
+
+			c.Set("A", []int{1, 2, 3})
+			assert.Equal(t, "B", c.EvictionOrder()[0])
+			val, _ := c.Get("A")
+			assert.Equal(t, []int{1, 2, 3}, val)
+		})
+	}
+}
+
+// Test `EqualFunc` decides which values `SkipIfEqual` considers unchanged
+func TestSkipIfEqualFunc(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        2,
+		SkipIfEqual:    true,
+		EqualFunc: func(stored, value any) bool {
+			return stored.(string)[0] == value.(string)[0]
+		},
+	})
+
+	c.Set("A", "apple")
+	c.Set("B", "banana")
+
+	// Equal by EqualFunc, so the update is skipped
+	c.Set("A", "avocado")
+	assert.Equal(t, []string{"A", "B"}, c.EvictionOrder())
+	val, _ := c.Get("A")
+	assert.Equal(t, "apple", val)
+
+	c.Set("B", "cherry")
+	assert.Equal(t, []string{"A", "B"}, c.EvictionOrder())
+	val, _ = c.Get("B")
+	assert.Equal(t, "cherry", val)
+}
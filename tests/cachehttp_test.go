@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/cachehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// CacheHTTPTestSuite defines the test structure
+type CacheHTTPTestSuite struct {
+	suite.Suite
+	c *cache.Cache
+}
+
+// Setup before each test
+func (suite *CacheHTTPTestSuite) SetupTest() {
+	suite.c = cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        3,
+		Metrics:        true,
+	})
+	suite.c.Set("A", "Item A")
+	suite.c.Set("B", "Item B")
+}
+
+func (suite *CacheHTTPTestSuite) serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+// Test `GET /cache/stats`
+func (suite *CacheHTTPTestSuite) TestStats() {
+	suite.c.Get("A")
+	suite.c.Get("X")
+
+	rec := suite.serve(cachehttp.Handler(suite.c), http.MethodGet, "/cache/stats")
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "application/json", rec.Header().Get("Content-Type"))
+
+	var body map[string]any
+	assert.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(suite.T(), "lru", body["policy"])
+	assert.Equal(suite.T(), float64(2), body["len"])
+	assert.Contains(suite.T(), body, "hits")
+	assert.Equal(suite.T(), float64(1), body["misses"])
+	assert.Contains(suite.T(), body, "hit_rate")
+}
+
+// Test admin endpoints are disabled by default
+func (suite *CacheHTTPTestSuite) TestAdminDisabled() {
+	h := cachehttp.Handler(suite.c)
+
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serve(h, http.MethodGet, "/cache/keys").Code)
+	assert.NotEqual(suite.T(), http.StatusNoContent, suite.serve(h, http.MethodDelete, "/cache").Code)
+	assert.Equal(suite.T(), 2, suite.c.Len())
+}
+
+// Test `GET /cache/keys` and `DELETE /cache`
+func (suite *CacheHTTPTestSuite) TestAdminEnabled() {
+	h := cachehttp.NewHandler(suite.c, cachehttp.Options{ExposeKeys: true, AllowFlush: true})
+
+	rec := suite.serve(h, http.MethodGet, "/cache/keys")
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	var keys []string
+	assert.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &keys))
+	assert.ElementsMatch(suite.T(), []string{"A", "B"}, keys)
+
+	rec = suite.serve(h, http.MethodDelete, "/cache")
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+	assert.Equal(suite.T(), 0, suite.c.Len())
+}
+
+// Run the test suite
+func TestCacheHTTPTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheHTTPTestSuite))
+}
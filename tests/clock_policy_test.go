@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClockTestSuite defines the test structure
+type ClockTestSuite struct {
+	suite.Suite
+	c *cache.Cache
+}
+
+// Setup before each test
+func (suite *ClockTestSuite) SetupTest() {
+	suite.c = cache.New(&cache.Config{
+		EvictionPolicy: cache.Clock,
+		MaxSize:        3,
+	})
+}
+
+// Test a recently accessed old item survives one eviction cycle
+func (suite *ClockTestSuite) TestClockSecondChance() {
+	suite.c.Set("A", "Item A")
+	suite.c.Set("B", "Item B")
+	suite.c.Set("C", "Item C")
+
+	suite.c.Get("A")
+	assert.Equal(suite.T(), []string{"B", "C", "A"}, suite.c.EvictionOrder())
+
+	suite.c.Set("D", "Item D")
+	assert.True(suite.T(), suite.c.Has("A"))
+	assert.False(suite.T(), suite.c.Has("B"))
+
+	suite.c.Set("E", "Item E")
+	assert.True(suite.T(), suite.c.Has("A"))
+	assert.False(suite.T(), suite.c.Has("C"))
+
+	// The second chance is used up, so A is evicted next
+	suite.c.Set("F", "Item F")
+	assert.False(suite.T(), suite.c.Has("A"))
+	assert.True(suite.T(), suite.c.Has("D"))
+	assert.True(suite.T(), suite.c.Has("E"))
+	assert.True(suite.T(), suite.c.Has("F"))
+}
+
+// Test Clock behaves like FIFO without accesses
+func (suite *ClockTestSuite) TestClockWithoutAccess() {
+	suite.c.Set("A", "Item A")
+	suite.c.Set("B", "Item B")
+	suite.c.Set("C", "Item C")
+	suite.c.Set("D", "Item D")
+
+	assert.False(suite.T(), suite.c.Has("A"))
+	assert.Equal(suite.T(), []string{"B", "C", "D"}, suite.c.EvictionOrder())
+}
+
+// Test every item referenced falls back to FIFO order
+func (suite *ClockTestSuite) TestClockAllReferenced() {
+	suite.c.Set("A", "Item A")
+	suite.c.Set("B", "Item B")
+	suite.c.Set("C", "Item C")
+	suite.c.Get("A")
+	suite.c.Get("B")
+	suite.c.Get("C")
+
+	key, evicted := suite.c.EvictKey()
+	assert.True(suite.T(), evicted)
+	assert.Equal(suite.T(), "A", key)
+
+	suite.c.Delete("B")
+	key, _ = suite.c.EvictKey()
+	assert.Equal(suite.T(), "C", key)
+	assert.Equal(suite.T(), 0, suite.c.Len())
+}
+
+// Run the test suite
+func TestClockTestSuite(t *testing.T) {
+	suite.Run(t, new(ClockTestSuite))
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a manually controlled clock for `Config.Clock`
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the current time, then advances it by the configured step
+func (f *fakeClock) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.now
+	f.now = f.now.Add(f.step)
+	return now
+}
+
+// Advance moves the clock forward
+func (f *fakeClock) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+// SetStep sets how much the clock advances on every call to Now
+func (f *fakeClock) SetStep(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.step = d
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// CompressTestSuite defines the test structure
+type CompressTestSuite struct {
+	suite.Suite
+	c *cache.Cache
+}
+
+// Setup before each test
+func (suite *CompressTestSuite) SetupTest() {
+	suite.c = cache.New(&cache.Config{
+		EvictionPolicy:   cache.LRU,
+		MaxSize:          10,
+		Compress:         true,
+		CompressMinBytes: 64,
+	})
+}
+
+// Test large string values round-trip
+func (suite *CompressTestSuite) TestLargeString() {
+	value := strings.Repeat(`{"status":"ok"}`, 100)
+	suite.c.Set("A", value)
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), value, val)
+}
+
+// Test large byte values round-trip through compression
+func (suite *CompressTestSuite) TestLargeBytes() {
+	value := bytes.Repeat([]byte("abcdef"), 100)
+	suite.c.Preload(map[string]any{"A": value})
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), value, val)
+
+	// A decompressed value is a new slice
+	assert.NotSame(suite.T(), &value[0], &val.([]byte)[0])
+}
+
+// Test small values skip compression
+func (suite *CompressTestSuite) TestSmallValues() {
+	value := []byte("small")
+	suite.c.Preload(map[string]any{"A": value})
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Same(suite.T(), &value[0], &val.([]byte)[0])
+
+	suite.c.Set("B", "small")
+	val, _ = suite.c.Get("B")
+	assert.Equal(suite.T(), "small", val)
+}
+
+// Test the size estimate counts compressed values by their compressed size
+func TestCompressEstimatedBytes(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:   cache.LRU,
+		MaxSize:          10,
+		Compress:         true,
+		CompressMinBytes: 64,
+		EstimateSize:     true,
+	})
+
+	value := strings.Repeat("a", 10000)
+	c.Set("A", value)
+	c.SetBytes("B", []byte(value))
+	c.SetWithExpireCallback("C", value, 0, func(any) {})
+	assert.Less(t, c.EstimatedBytes(), uint64(1000))
+
+	c.Set("D", "small")
+	before := c.EstimatedBytes()
+	c.Delete("D")
+	assert.Equal(t, before-uint64(len("D")+len("small")), c.EstimatedBytes())
+}
+
+// Test values below the threshold cost no more to set with compression enabled
+func TestCompressSmallValueAllocs(t *testing.T) {
+	value := strings.Repeat("a", 512)
+	allocs := func(compress bool) float64 {
+		c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10, Compress: compress})
+		return testing.AllocsPerRun(100, func() { c.Set("A", value) })
+	}
+
+	assert.Equal(t, allocs(false), allocs(true))
+}
+
+// Run the test suite
+func TestCompressTestSuite(t *testing.T) {
+	suite.Run(t, new(CompressTestSuite))
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/lfu"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Validate()` and `NewWithError()` with invalid configs
+func TestConfigValidate(t *testing.T) {
+	invalid := map[string]*cache.Config{
+		"unknown policy":           {EvictionPolicy: cache.EvictionPolicy(42)},
+		"custom without engine":    {EvictionPolicy: cache.Custom},
+		"negative max size":        {EvictionPolicy: cache.LRU, MaxSize: -1},
+		"negative ttl":             {EvictionPolicy: cache.Basic, TTL: -time.Second},
+		"memory limit no interval": {EvictionPolicy: cache.LRU, MemoryLimits: 1024},
+		"negative batch size":      {EvictionPolicy: cache.LRU, EvictBatchSize: -1},
+		"cleanup fraction above 1": {EvictionPolicy: cache.Basic, CleanupBatchFraction: 1.5},
+		"lfu score and approx":     {EvictionPolicy: cache.LFU, LFUScore: lfu.DefaultScore, LFUApproxCounters: true},
+		"negative lfu frequency":   {EvictionPolicy: cache.LFU, LFUMaxFrequency: -1},
+		"negative load waiters":    {EvictionPolicy: cache.LRU, MaxLoadWaiters: -1},
+		"negative ghost size":      {EvictionPolicy: cache.LRU, EvictionGhostSize: -1},
+		"low above high watermark": {EvictionPolicy: cache.LRU, MemoryHighWatermark: 10, MemoryLowWatermark: 20, MemoryCheckInterval: time.Second},
+		"unknown memory eviction":  {EvictionPolicy: cache.LRU, MemoryEviction: cache.MemoryEviction(42)},
+		"cleanup bounds inverted":  {EvictionPolicy: cache.Basic, MinCleanupInterval: time.Minute, MaxCleanupInterval: time.Second},
+		"negative min TTL":         {EvictionPolicy: cache.Basic, MinTTL: -time.Second},
+		"negative refresh lead":    {EvictionPolicy: cache.Basic, RefreshAheadLead: -time.Second},
+	}
+
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, cfg.Validate(), cache.ErrInvalidConfig)
+
+			c, err := cache.NewWithError(cfg)
+			assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+			assert.Nil(t, c)
+		})
+	}
+
+	c, err := cache.NewWithError(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+
+	c, err = cache.NewWithError(nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+}
+
+// Test the cache keeps its own copy of the config
+func TestConfigImmutableAfterNew(t *testing.T) {
+	cfg := &cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        2,
+	}
+	c := cache.New(cfg)
+
+	cfg.MaxSize = 10
+	cfg.EvictionPolicy = cache.LRU
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+
+	assert.Equal(t, 2, c.Len())
+	assert.False(t, c.Has("A"))
+	assert.Equal(t, cache.FIFO, c.Policy())
+
+	// New does not write defaults back into the caller's struct
+	assert.Equal(t, time.Duration(0), cfg.CleanupInterval)
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test mutating a value returned with `CopyOnGet` leaves the cached value unchanged
+func TestCopyOnGet(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		CopyOnGet:      true,
+	})
+
+	c.Set("slice", []int{1, 2, 3})
+	c.Set("map", map[string]int{"a": 1})
+
+	val, _ := c.Get("slice")
+	val.([]int)[0] = 42
+	val, _ = c.Get("slice")
+	assert.Equal(t, []int{1, 2, 3}, val)
+
+	val, _ = c.Get("map")
+	val.(map[string]int)["a"] = 42
+	val, _ = c.Get("map")
+	assert.Equal(t, map[string]int{"a": 1}, val)
+
+	s, err := cache.GetAs[[]int](c, "slice")
+	assert.NoError(t, err)
+	s[1] = 42
+	val, _ = c.Get("slice")
+	assert.Equal(t, []int{1, 2, 3}, val)
+}
+
+// Test `CopyFunc` replaces the default shallow copy
+func TestCopyFunc(t *testing.T) {
+	type point struct{ X, Y int }
+
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		CopyOnGet:      true,
+		CopyFunc: func(value any) any {
+			p := *value.(*point)
+			return &p
+		},
+	})
+
+	c.Set("p", &point{X: 1, Y: 2})
+
+	val, _ := c.Get("p")
+	val.(*point).X = 42
+	val, _ = c.Get("p")
+	assert.Equal(t, &point{X: 1, Y: 2}, val)
+}
+
+// Test `ShallowCopy` copies slices and maps and returns other values unchanged
+func TestShallowCopy(t *testing.T) {
+	var nilSlice []int
+	assert.Nil(t, cache.ShallowCopy(nilSlice))
+	assert.Equal(t, "value", cache.ShallowCopy("value"))
+	assert.Equal(t, []string{"a"}, cache.ShallowCopy([]string{"a"}))
+	assert.Equal(t, map[int]bool{1: true}, cache.ShallowCopy(map[int]bool{1: true}))
+	assert.Nil(t, cache.ShallowCopy(nil))
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Configure()` and `Default()`
+func TestDefaultCache(t *testing.T) {
+	configured := cache.Configure(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        2,
+	})
+	assert.True(t, configured)
+
+	// Configure after first use has no effect
+	assert.False(t, cache.Configure(&cache.Config{EvictionPolicy: cache.Basic, TTL: time.Minute}))
+
+	assert.Same(t, cache.Default(), cache.Default())
+	assert.Equal(t, cache.FIFO, cache.Default().Policy())
+	assert.True(t, cache.Default().IsExpirable())
+
+	cache.Set("A", "Item A")
+	cache.Set("B", "Item B")
+	cache.Set("C", "Item C")
+
+	// FIFO with MaxSize 2 evicted "A"
+	_, found := cache.Get("A")
+	assert.False(t, found)
+
+	val, found := cache.Default().Get("C")
+	assert.True(t, found)
+	assert.Equal(t, "Item C", val)
+
+	cache.Delete("C")
+	_, found = cache.Get("C")
+	assert.False(t, found)
+	assert.Equal(t, 1, cache.Default().Len())
+}
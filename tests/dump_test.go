@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Dump()` on a non-expirable cache
+func TestDump(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	c.Set("A", "Item A")
+	c.Preload(map[string]any{"B": 42})
+	c.Set("C", strings.Repeat("x", 1000))
+	c.Get("A")
+
+	dump := c.Dump()
+	assert.Contains(t, dump, "policy=lru len=3")
+	assert.Contains(t, dump, `"A" (string) = Item A`)
+	assert.Contains(t, dump, `"B" (int) = 42`)
+	assert.Contains(t, dump, strings.Repeat("x", 64)+"...")
+	assert.NotContains(t, dump, strings.Repeat("x", 65))
+	assert.NotContains(t, dump, "ttl=")
+
+	// Keys are listed in eviction order
+	assert.Less(t, strings.Index(dump, `"B"`), strings.Index(dump, `"C"`))
+	assert.Less(t, strings.Index(dump, `"C"`), strings.Index(dump, `"A"`))
+}
+
+// Test `Dump()` reports remaining TTLs
+func TestDumpTTL(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.Basic,
+		TTL:            time.Minute,
+	})
+
+	c.Set("A", "Item A")
+
+	dump := c.Dump()
+	assert.Contains(t, dump, "policy=basic len=1")
+	assert.Contains(t, dump, `"A" (string) = Item A ttl=`)
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/basic"
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/clock"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/hugocarreira/easycache/engine/enginetest"
+	"github.com/hugocarreira/easycache/fifo"
+	"github.com/hugocarreira/easycache/lfu"
+	"github.com/hugocarreira/easycache/lru"
+	"github.com/hugocarreira/easycache/lruk"
+	"github.com/hugocarreira/easycache/tiered"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test every engine against the `engine.Engine` contract
+func TestEngineConformance(t *testing.T) {
+	factories := map[string]func() engine.Engine{
+		"basic": func() engine.Engine {
+			e := basic.New(0, time.Minute, time.Hour)
+			t.Cleanup(e.(engine.Closer).Close)
+			return e
+		},
+		"fifo":       func() engine.Engine { return fifo.New(0) },
+		"lru":        func() engine.Engine { return lru.New(0) },
+		"lfu":        func() engine.Engine { return lfu.New(0) },
+		"lfu-heap":   func() engine.Engine { return lfu.NewWithScore(0, nil) },
+		"lfu-approx": func() engine.Engine { return lfu.NewApprox(0) },
+		"lruk":       func() engine.Engine { return lruk.New(0, 0) },
+		"clock":      func() engine.Engine { return clock.New(0) },
+		"tiered": func() engine.Engine {
+			return tiered.New(fifo.New(0), fifo.New(0), tiered.Options{})
+		},
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			enginetest.RunEngineConformance(t, factory)
+		})
+	}
+}
+
+// lifoEngine is a minimal custom engine evicting the most recently inserted key.
+type lifoEngine struct {
+	items map[string]any
+	order []string
+}
+
+func newLIFOEngine() *lifoEngine {
+	return &lifoEngine{items: make(map[string]any)}
+}
+
+func (e *lifoEngine) Get(key string) (any, bool) {
+	value, ok := e.items[key]
+	return value, ok
+}
+
+func (e *lifoEngine) Peek(key string) (any, time.Time, bool) {
+	value, ok := e.items[key]
+	return value, time.Time{}, ok
+}
+
+func (e *lifoEngine) Set(key string, value any) {
+	if _, ok := e.items[key]; !ok {
+		e.order = append(e.order, key)
+	}
+	e.items[key] = value
+}
+
+func (e *lifoEngine) SetWithTTL(key string, value any, _ time.Time) { e.Set(key, value) }
+
+func (e *lifoEngine) Delete(key string) {
+	if _, ok := e.items[key]; !ok {
+		return
+	}
+	delete(e.items, key)
+	for i, k := range e.order {
+		if k == key {
+			e.order = append(e.order[:i], e.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (e *lifoEngine) Has(key string) bool          { _, ok := e.items[key]; return ok }
+func (e *lifoEngine) Len() int                     { return len(e.items) }
+func (e *lifoEngine) IsExpirable() bool            { return false }
+func (e *lifoEngine) IsExpired(string) bool        { return false }
+func (e *lifoEngine) Touch(string, time.Time) bool { return false }
+func (e *lifoEngine) Clear()                       { e.items, e.order = make(map[string]any), nil }
+func (e *lifoEngine) Range(fn func(key string, value any) bool) {
+	for k, v := range e.items {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+func (e *lifoEngine) Evict() (string, any, bool) {
+	if len(e.order) == 0 {
+		return "", nil, false
+	}
+	key := e.order[len(e.order)-1]
+	value := e.items[key]
+	e.Delete(key)
+	return key, value, true
+}
+
+func (e *lifoEngine) Keys() []string {
+	keys := make([]string, len(e.order))
+	for i, k := range e.order {
+		keys[len(keys)-1-i] = k
+	}
+	return keys
+}
+
+// Test `CustomEngine` replaces the built-in engine behind the `Cache` facade
+func TestCustomEngine(t *testing.T) {
+	var sized int
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        3,
+		Metrics:        true,
+		CustomEngine: func(maxSize int) engine.Engine {
+			sized = maxSize
+			return newLIFOEngine()
+		},
+	})
+	defer c.Close()
+	assert.Equal(t, 3, sized)
+	assert.Equal(t, cache.Custom, c.Policy())
+	assert.Equal(t, cache.Custom, c.Stats().Policy)
+	assert.ErrorIs(t, c.SwitchPolicy(cache.Custom), cache.ErrInvalidConfig)
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+	c.Get("A")
+
+	// LRU would evict B; the custom engine evicts the last inserted key
+	c.Set("D", "Item D")
+	assert.False(t, c.Has("C"))
+	assert.True(t, c.Has("A"))
+	assert.True(t, c.Has("B"))
+	assert.True(t, c.Has("D"))
+	assert.Equal(t, int64(1), c.Metrics().Evictions())
+
+	val, found := c.Get("B")
+	assert.True(t, found)
+	assert.Equal(t, "Item B", val)
+
+	c.Delete("B")
+	assert.Equal(t, 2, c.Len())
+	assert.ElementsMatch(t, []string{"A", "D"}, c.Keys())
+
+	// Switching to a built-in policy keeps the entries
+	assert.NoError(t, c.SwitchPolicy(cache.FIFO))
+	assert.Equal(t, cache.FIFO, c.Policy())
+	assert.Equal(t, 2, c.Len())
+}
+
+// Test the Custom policy without a `CustomEngine` reports the Basic engine it gets
+func TestCustomPolicyWithoutEngine(t *testing.T) {
+	cfg := &cache.Config{EvictionPolicy: cache.Custom}
+
+	_, err := cache.NewWithError(cfg)
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+
+	c := cache.New(cfg)
+	defer c.Close()
+	assert.Equal(t, cache.Basic, c.Policy())
+	assert.Equal(t, cache.Basic, c.Stats().Policy)
+}
+
+// reservingEngine is a lifoEngine recording the capacity it was reserved for
+type reservingEngine struct {
+	*lifoEngine
+	reserved int
+}
+
+func (e *reservingEngine) Reserve(n int) {
+	e.reserved = n
+}
+
+// Test `InitialCapacity` is reserved on a custom engine
+func TestCustomEngineInitialCapacity(t *testing.T) {
+	e := &reservingEngine{lifoEngine: newLIFOEngine()}
+	c := cache.New(&cache.Config{
+		MaxSize:         100,
+		InitialCapacity: 50,
+		CustomEngine:    func(int) engine.Engine { return e },
+	})
+	defer c.Close()
+
+	assert.Equal(t, 50, e.reserved)
+}
+
+// expiringEngine is a lifoEngine reporting a fixed set of keys as expired
+type expiringEngine struct {
+	*lifoEngine
+	expired map[string]bool
+}
+
+func (e *expiringEngine) IsExpirable() bool         { return true }
+func (e *expiringEngine) IsExpired(key string) bool { return e.expired[key] }
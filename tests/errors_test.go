@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the error-returning variants on an open cache
+func TestErrorVariants(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	defer c.Close()
+
+	assert.NoError(t, c.SetE("A", 42))
+
+	val, err := c.GetE("A")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, val)
+
+	_, err = c.GetE("missing")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	assert.NoError(t, c.DeleteE("A"))
+	assert.ErrorIs(t, c.DeleteE("A"), cache.ErrNotFound)
+}
+
+// Test `GetAs()` type checks
+func TestGetAs(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+
+	val, err := cache.GetAs[string](c, "A")
+	assert.NoError(t, err)
+	assert.Equal(t, "Item A", val)
+
+	_, err = cache.GetAs[int](c, "A")
+	assert.ErrorIs(t, err, cache.ErrWrongType)
+
+	_, err = cache.GetAs[string](c, "missing")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+}
+
+// Test the error-returning variants on a closed cache
+func TestErrorVariantsClosed(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10})
+			c.Set("A", "Item A")
+
+			c.Close()
+			c.Close()
+
+			_, err := c.GetE("A")
+			assert.ErrorIs(t, err, cache.ErrClosed)
+			assert.ErrorIs(t, c.SetE("B", "Item B"), cache.ErrClosed)
+			assert.ErrorIs(t, c.DeleteE("A"), cache.ErrClosed)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitEvent(t *testing.T, events <-chan cache.Event) cache.Event {
+	t.Helper()
+
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+		return cache.Event{}
+	}
+}
+
+// Test `Events()` on policy eviction
+func TestEventsEviction(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        1,
+	})
+	events := c.Events()
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	ev := waitEvent(t, events)
+	assert.Equal(t, "A", ev.Key)
+	assert.Equal(t, cache.ReasonEvicted, ev.Reason)
+}
+
+// Test `Events()` on TTL expiry
+func TestEventsExpiry(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             20 * time.Millisecond,
+		CleanupInterval: 10 * time.Millisecond,
+	})
+	events := c.Events()
+
+	c.Set("A", "Item A")
+
+	ev := waitEvent(t, events)
+	assert.Equal(t, "A", ev.Key)
+	assert.Equal(t, cache.ReasonExpired, ev.Reason)
+}
+
+// Test slow consumers make events drop instead of blocking
+func TestEventsDropped(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.FIFO,
+		MaxSize:         1,
+		EventBufferSize: 2,
+	})
+	events := c.Events()
+
+	for _, key := range []string{"A", "B", "C", "D", "E"} {
+		c.Set(key, "value")
+	}
+
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, uint64(2), c.DroppedEvents())
+}
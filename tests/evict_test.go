@@ -0,0 +1,353 @@
+package tests
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/lfu"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Evict()` with `EvictBatchSize` across policies
+func TestEvictBatchSize(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.FIFO, cache.LRU, cache.LFU} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				EvictBatchSize: 3,
+			})
+
+			for i := 0; i < 10; i++ {
+				c.Set(fmt.Sprintf("key-%d", i), "value")
+			}
+
+			c.Evict()
+			assert.Equal(t, 7, c.Len())
+
+			c.Evict()
+			c.Evict()
+			assert.Equal(t, 1, c.Len())
+
+			// The last batch stops when the cache is empty
+			c.Evict()
+			assert.Equal(t, 0, c.Len())
+		})
+	}
+}
+
+// Test `Evict()` removes a single item by default
+func TestEvictDefaultBatchSize(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+	})
+
+	for i := 0; i < 5; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+
+	c.Evict()
+	assert.Equal(t, 4, c.Len())
+}
+
+// Test `EvictionOrder()` per policy
+func TestEvictionOrder(t *testing.T) {
+	expected := map[cache.EvictionPolicy][]string{
+		cache.FIFO: {"A", "B", "C", "D"},
+		cache.LRU:  {"D", "B", "C", "A"},
+		cache.LFU:  {"D", "B", "A", "C"},
+	}
+
+	for policy, order := range expected {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+			})
+
+			c.Set("A", "Item A")
+			c.Set("B", "Item B")
+			c.Set("C", "Item C")
+			c.Set("D", "Item D")
+
+			c.Get("B")
+			c.Get("C")
+			c.Get("C")
+			c.Get("A")
+
+			assert.Equal(t, order, c.EvictionOrder())
+
+			// The reported order matches the actual evictions
+			for _, key := range order {
+				c.Evict()
+				assert.False(t, c.Has(key))
+			}
+		})
+	}
+}
+
+// Test `EvictKey()` returns the policy's expected victim
+func TestEvictKey(t *testing.T) {
+	expected := map[cache.EvictionPolicy]string{
+		cache.FIFO:  "A",
+		cache.LRU:   "B",
+		cache.LFU:   "B",
+		cache.LRUK:  "B",
+		cache.Basic: "A",
+	}
+
+	for policy, victim := range expected {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			clock.SetStep(time.Millisecond)
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+				Metrics:        true,
+				Clock:          clock.Now,
+			})
+
+			c.Set("A", "Item A")
+			c.Set("B", "Item B")
+			c.Get("A")
+
+			key, evicted := c.EvictKey()
+			assert.True(t, evicted)
+			assert.Equal(t, victim, key)
+			assert.False(t, c.Has(victim))
+			assert.Equal(t, int64(1), c.Metrics().Evictions())
+		})
+	}
+}
+
+// Test `EvictKey()` on an empty cache
+func TestEvictKeyEmpty(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	key, evicted := c.EvictKey()
+	assert.False(t, evicted)
+	assert.Empty(t, key)
+}
+
+// seededDecisions replays a fixed workload with a seeded `Config.Rand` and
+// returns the evicted keys and the early recomputation decisions
+func seededDecisions(seed uint64) ([]string, []bool) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		MaxSize:         10,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		EarlyRecompute:  1,
+		Clock:           clock.Now,
+		Rand:            rand.New(rand.NewPCG(seed, seed)),
+	})
+	defer c.Close()
+
+	events := c.Events()
+
+	// Every item expires at the same time, so eviction has to break ties
+	for i := 0; i < 50; i++ {
+		c.SetWithComputeTime(fmt.Sprintf("key-%d", i), i, time.Second)
+	}
+
+	var evicted []string
+	for len(events) > 0 {
+		evicted = append(evicted, (<-events).Key)
+	}
+
+	survivor := c.EvictionOrder()[0]
+	clock.Advance(time.Minute - time.Second)
+	recomputed := make([]bool, 100)
+	for i := range recomputed {
+		_, found := c.Get(survivor)
+		recomputed[i] = !found
+	}
+
+	return evicted, recomputed
+}
+
+// Test the same seeded sequence makes the same eviction and recomputation decisions
+func TestSeededRandReproducible(t *testing.T) {
+	evicted, recomputed := seededDecisions(42)
+	assert.Len(t, evicted, 40)
+	assert.Contains(t, recomputed, true)
+	assert.Contains(t, recomputed, false)
+
+	// The ties are broken by the seeded source, not by insertion order
+	inserted := make([]string, 40)
+	for i := range inserted {
+		inserted[i] = fmt.Sprintf("key-%d", i)
+	}
+	assert.NotEqual(t, inserted, evicted)
+
+	for i := 0; i < 5; i++ {
+		again, recomputedAgain := seededDecisions(42)
+		assert.Equal(t, evicted, again)
+		assert.Equal(t, recomputed, recomputedAgain)
+	}
+
+	otherEvicted, otherRecomputed := seededDecisions(7)
+	assert.NotEqual(t, evicted, otherEvicted)
+	assert.NotEqual(t, recomputed, otherRecomputed)
+}
+
+// Test Basic evicts items expiring at the same time by insertion without `Rand`
+func TestBasicTiesByInsertion(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		MaxSize:         3,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	for _, key := range []string{"A", "B", "C", "D", "E"} {
+		c.Set(key, "value")
+	}
+
+	assert.Equal(t, []string{"C", "D", "E"}, c.EvictionOrder())
+}
+
+// Test a cache with `MaxSize` 1 always keeps exactly the most recent key
+func TestMaxSizeOne(t *testing.T) {
+	configs := map[string]*cache.Config{
+		"lfu-score":  {EvictionPolicy: cache.LFU, LFUScore: lfu.DefaultScore},
+		"lfu-approx": {EvictionPolicy: cache.LFU, LFUApproxCounters: true},
+		"batch":      {EvictionPolicy: cache.LRU, EvictBatchSize: 4},
+	}
+	for _, policy := range allPolicies {
+		configs[policy.String()] = &cache.Config{EvictionPolicy: policy, TTL: time.Minute}
+	}
+
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			cfg.MaxSize = 1
+			cfg.Metrics = true
+			c := cache.New(cfg)
+			defer c.Close()
+
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				c.Set(key, i)
+
+				// Reading and replacing the only key doesn't evict it
+				c.Get(key)
+				c.Set(key, i)
+
+				assert.Equal(t, []string{key}, c.Keys())
+				assert.Equal(t, 1, c.Len())
+			}
+			assert.Equal(t, int64(99), c.Metrics().Evictions())
+
+			c.SetWeighted("weighted", 1, 10)
+			c.SetNX("nx", 1, time.Minute)
+			c.SetBytes("bytes", []byte("value"))
+			assert.Equal(t, map[string]any{"bytes": []byte("value")}, c.Entries())
+		})
+	}
+}
+
+// Test an approximate LFU cache with a huge `MaxSize` bounds its metadata
+func TestLFUApproxHugeMaxSize(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LFU, MaxSize: math.MaxInt, LFUApproxCounters: true})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	val, found := c.Get("A")
+	assert.True(t, found)
+	assert.Equal(t, "Item A", val)
+}
+
+// Test `CanEvict` vetoes candidates so the next one in eviction order is evicted
+func TestCanEvict(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.FIFO, cache.LRU, cache.LFU} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        3,
+				Metrics:        true,
+				CanEvict: func(key string, value any) bool {
+					return key != "A" && value != "in use"
+				},
+			})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			c.Set("B", "in use")
+			c.Set("C", "Item C")
+
+			// A and B come first in eviction order, but are vetoed
+			c.Set("D", "Item D")
+			assert.ElementsMatch(t, []string{"A", "B", "D"}, c.Keys())
+
+			c.Set("E", "Item E")
+			assert.ElementsMatch(t, []string{"A", "B", "E"}, c.Keys())
+			assert.Equal(t, int64(2), c.Metrics().Evictions())
+		})
+	}
+}
+
+// Test the first candidate is evicted when `CanEvict` vetoes every one
+func TestCanEvictVetoesAll(t *testing.T) {
+	var asked []string
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        3,
+		CanEvict: func(key string, _ any) bool {
+			asked = append(asked, key)
+			return false
+		},
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+	c.Pin("A")
+
+	c.Set("D", "Item D")
+	assert.Equal(t, []string{"B", "C"}, asked)
+	assert.Equal(t, 3, c.Len())
+	assert.ElementsMatch(t, []string{"A", "C", "D"}, c.Keys())
+}
+
+// Test `EvictionOrder()` leaves pinned keys out and lists vetoed keys last
+func TestEvictionOrderPinsAndVetoes(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.FIFO, cache.LRU, cache.LFU} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				CanEvict: func(_ string, value any) bool {
+					return value != "in use"
+				},
+			})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			c.Set("B", "in use")
+			c.Set("C", "Item C")
+			c.Set("D", "Item D")
+			c.Pin("C")
+
+			order := []string{"A", "D", "B"}
+			assert.Equal(t, order, c.EvictionOrder())
+
+			// The reported order matches the actual evictions
+			for _, key := range order {
+				c.Evict()
+				assert.False(t, c.Has(key))
+			}
+			assert.True(t, c.Has("C"))
+		})
+	}
+}
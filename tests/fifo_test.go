@@ -2,6 +2,7 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hugocarreira/easycache/cache"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,41 @@ func (suite *FIFOTestSuite) TestFIFOEviction() {
 	assert.True(suite.T(), suite.c.Has("C"))
 }
 
+// Test FIFO with TTL evicts the oldest item on capacity and expires items by time
+func (suite *FIFOTestSuite) TestFIFOWithTTL() {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        3,
+		TTL:            time.Minute,
+		Clock:          clock.Now,
+	})
+
+	c.Set("A", "Item A")
+	clock.Advance(30 * time.Second)
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+
+	// Capacity evicts the oldest item, regardless of its TTL
+	c.Set("D", "Item D")
+	assert.False(suite.T(), c.Has("A"))
+	assert.Equal(suite.T(), []string{"B", "C", "D"}, c.EvictionOrder())
+
+	// "B" and "C" expire, "D" (stored with a later deadline) survives
+	c.SetWithDeadline("D", "Item D", clock.Now().Add(time.Hour))
+	clock.Advance(2 * time.Minute)
+	assert.False(suite.T(), c.Has("B"))
+	_, found := c.Get("C")
+	assert.False(suite.T(), found)
+	assert.True(suite.T(), c.Has("D"))
+	assert.Equal(suite.T(), 1, c.Len())
+
+	// Expired items free their slots, so no live item is evicted
+	c.Set("E", "Item E")
+	c.Set("F", "Item F")
+	assert.ElementsMatch(suite.T(), []string{"D", "E", "F"}, c.Keys())
+}
+
 // Run the test suite
 func TestFIFOTestSuite(t *testing.T) {
 	suite.Run(t, new(FIFOTestSuite))
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/basic"
+	"github.com/hugocarreira/easycache/clock"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/hugocarreira/easycache/fifo"
+	"github.com/hugocarreira/easycache/lfu"
+	"github.com/hugocarreira/easycache/lru"
+	"github.com/hugocarreira/easycache/lruk"
+	"github.com/hugocarreira/easycache/tiered"
+)
+
+// fuzzEngines returns a new engine of every kind, with no size limit so the
+// fuzzed operations alone decide what they hold
+func fuzzEngines() map[string]engine.Engine {
+	return map[string]engine.Engine{
+		"basic":      basic.New(0, 0, time.Hour),
+		"fifo":       fifo.New(0),
+		"lru":        lru.New(0),
+		"lfu":        lfu.New(0),
+		"lfu-heap":   lfu.NewWithScore(0, nil),
+		"lfu-approx": lfu.NewApprox(0),
+		"lruk":       lruk.New(0, 0),
+		"clock":      clock.New(0),
+		"tiered":     tiered.New(fifo.New(0), fifo.New(0), tiered.Options{}),
+	}
+}
+
+// fuzzKeys is the number of distinct keys used by FuzzEngine, small enough
+// for operations to keep hitting the same keys
+const fuzzKeys = 8
+
+// FuzzEngine runs random sequences of operations on every engine and checks
+// them against a map, along with the consistency of Len, Keys and Evict.
+//
+// Every pair of bytes of the input is an operation and a key: Set, SetWeighted,
+// Get, Peek, Delete, Evict or Clear.
+func FuzzEngine(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 1, 2, 0, 5, 0, 5, 0})
+	f.Add([]byte{0, 1, 1, 2, 2, 1, 2, 1, 5, 0, 4, 2, 5, 0, 6, 0, 0, 3})
+	f.Add([]byte{1, 0, 1, 1, 1, 2, 2, 0, 2, 0, 5, 0, 3, 1, 5, 0, 5, 0})
+	f.Add([]byte{0, 0, 0, 1, 0, 2, 0, 3, 4, 1, 4, 2, 0, 1, 5, 0, 5, 0, 5, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		for name, e := range fuzzEngines() {
+			if closer, ok := e.(engine.Closer); ok {
+				defer closer.Close()
+			}
+
+			model := make(map[string]any)
+			for i := 0; i+1 < len(ops); i += 2 {
+				key := fmt.Sprintf("key-%d", ops[i+1]%fuzzKeys)
+				value := fmt.Sprintf("value-%d", i)
+
+				switch ops[i] % 7 {
+				case 0:
+					e.Set(key, value)
+					model[key] = value
+				case 1:
+					if weighted, ok := e.(engine.Weighted); ok {
+						weighted.SetWeighted(key, value, float64(ops[i+1]))
+					} else {
+						e.Set(key, value)
+					}
+					model[key] = value
+				case 2:
+					got, found := e.Get(key)
+					want, exists := model[key]
+					if found != exists || got != want {
+						t.Fatalf("%s: Get(%q) = %v, %v, want %v, %v", name, key, got, found, want, exists)
+					}
+				case 3:
+					got, _, found := e.Peek(key)
+					want, exists := model[key]
+					if found != exists || got != want || e.Has(key) != exists {
+						t.Fatalf("%s: Peek(%q) = %v, %v, want %v, %v", name, key, got, found, want, exists)
+					}
+				case 4:
+					e.Delete(key)
+					delete(model, key)
+				case 5:
+					keys := e.Keys()
+					evictedKey, evictedValue, evicted := e.Evict()
+					if evicted != (len(model) > 0) {
+						t.Fatalf("%s: Evict() = %v with %d items", name, evicted, len(model))
+					}
+					if !evicted {
+						break
+					}
+					if evictedKey != keys[0] {
+						t.Fatalf("%s: Evict() removed %q, but Keys() announced %q", name, evictedKey, keys[0])
+					}
+					if model[evictedKey] != evictedValue {
+						t.Fatalf("%s: Evict() returned %v for %q, want %v", name, evictedValue, evictedKey, model[evictedKey])
+					}
+					delete(model, evictedKey)
+				case 6:
+					e.Clear()
+					clear(model)
+				}
+
+				if n := e.Len(); n != len(model) {
+					t.Fatalf("%s: Len() = %d after op %d, want %d", name, n, i/2, len(model))
+				}
+				if keys := e.Keys(); len(keys) != len(model) {
+					t.Fatalf("%s: Keys() = %v after op %d, want %d keys", name, keys, i/2, len(model))
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Increment()` creates, updates and restarts counters
+func TestIncrement(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Basic, Clock: clock.Now})
+	defer c.Close()
+
+	deadline := clock.Now().Add(time.Minute)
+	assert.Equal(t, 1, c.Increment("A", 1, deadline))
+	assert.Equal(t, 6, c.Increment("A", 5, deadline.Add(time.Hour)))
+	assert.Equal(t, 4, c.Increment("A", -2, deadline))
+
+	// A value that is not an int is replaced
+	c.Set("B", "Item B")
+	assert.Equal(t, 3, c.Increment("B", 3, time.Time{}))
+
+	// The counter keeps its first deadline, then starts over
+	clock.Advance(time.Minute + time.Second)
+	assert.False(t, c.Has("A"))
+	assert.Equal(t, 1, c.Increment("A", 1, clock.Now().Add(time.Minute)))
+}
+
+// Test concurrent `Increment()` calls are never lost
+func TestIncrementConcurrent(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10})
+			defer c.Close()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 20; j++ {
+						c.Increment("A", 1, time.Time{})
+					}
+				}()
+			}
+			wg.Wait()
+
+			val, found := c.Get("A")
+			assert.True(t, found)
+			assert.Equal(t, 1000, val)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `CompositeKey()` on ambiguous concatenations
+func TestCompositeKey(t *testing.T) {
+	assert.NotEqual(t, cache.CompositeKey("1", "23"), cache.CompositeKey("12", "3"))
+	assert.NotEqual(t, cache.CompositeKey("a|b"), cache.CompositeKey("a", "b"))
+	assert.NotEqual(t, cache.CompositeKey("1:a"), cache.CompositeKey("1", "a"))
+	assert.NotEqual(t, cache.CompositeKey(""), cache.CompositeKey())
+	assert.NotEqual(t, cache.CompositeKey("", ""), cache.CompositeKey(""))
+	assert.Equal(t, cache.CompositeKey("user", "42"), cache.CompositeKey("user", "42"))
+}
+
+// Test `CompositeKey()` never collides for different part lists
+func TestCompositeKeyNoCollisions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"", "1", "2", "|", ":", "a"}
+	seen := make(map[string]string)
+
+	for i := 0; i < 20000; i++ {
+		parts := make([]string, rng.Intn(4))
+		for j := range parts {
+			var b strings.Builder
+			for k := rng.Intn(3); k > 0; k-- {
+				b.WriteString(alphabet[rng.Intn(len(alphabet))])
+			}
+			parts[j] = b.String()
+		}
+
+		id := strings.Join(parts, "\x00") + "#" + string(rune('0'+len(parts)))
+		key := cache.CompositeKey(parts...)
+		if previous, ok := seen[key]; ok {
+			assert.Equal(t, previous, id, "collision on key %q", key)
+		}
+		seen[key] = id
+	}
+}
+
+// Test using `CompositeKey()` with `Set()` and `Get()`
+func TestCompositeKeyWithCache(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	c.Set(cache.CompositeKey("1", "23"), "first")
+	c.Set(cache.CompositeKey("12", "3"), "second")
+
+	val, found := c.Get(cache.CompositeKey("1", "23"))
+	assert.True(t, found)
+	assert.Equal(t, "first", val)
+	assert.Equal(t, 2, c.Len())
+}
+
+// Test `KeyTransform` makes keys differing only by case share an entry
+func TestKeyTransform(t *testing.T) {
+	policies := []cache.EvictionPolicy{cache.Basic, cache.FIFO, cache.LRU, cache.LFU}
+
+	for _, policy := range policies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+				KeyTransform:   strings.ToLower,
+			})
+			defer c.Close()
+
+			c.Set("Foo", "first")
+			c.Set("foo", "second")
+
+			assert.Equal(t, 1, c.Len())
+			assert.Equal(t, []string{"foo"}, c.Keys())
+
+			val, found := c.Get("FOO")
+			assert.True(t, found)
+			assert.Equal(t, "second", val)
+			assert.True(t, c.Has("fOo"))
+
+			c.Delete("FoO")
+			assert.False(t, c.Has("foo"))
+			assert.Equal(t, 0, c.Len())
+		})
+	}
+}
+
+// Test `KeyTransform` applies to namespaces and prefixes too
+func TestKeyTransformPrefixes(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		KeyTransform:   strings.ToLower,
+	})
+	defer c.Close()
+
+	users := c.Namespace("Users")
+	users.Set("Alice", "Item A")
+	users.Set("Bob", "Item B")
+	c.Set("Session:1", "Item C")
+	c.Set("Session:2", "Item D")
+
+	assert.Equal(t, []string{"alice", "bob"}, users.Keys())
+	assert.Equal(t, 1, users.DeletePrefix("AL"))
+	assert.Equal(t, 2, c.DeletePrefix("SESSION:"))
+
+	users.Clear()
+	assert.Empty(t, users.Keys())
+	assert.Equal(t, 0, c.Len())
+}
+
+// Test the shadow cache receives keys transformed once
+func TestKeyTransformShadow(t *testing.T) {
+	var calls atomic.Int32
+	cfg := cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		KeyTransform: func(key string) string {
+			calls.Add(1)
+			return strings.ToLower(key)
+		},
+	}
+
+	c := cache.New(&cfg)
+	defer c.Close()
+
+	shadow := cfg
+	shadow.EvictionPolicy = cache.FIFO
+	c.AttachShadow(shadow)
+
+	c.Set("Foo", "first")
+	_, found := c.Get("FOO")
+	assert.True(t, found)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int64(0), c.ShadowMetrics().Misses())
+}
+
+// Test `InternKeys` doesn't change how keys are stored and looked up
+func TestInternKeys(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        2,
+				InternKeys:     true,
+				KeyTransform:   strings.ToLower,
+			})
+			defer c.Close()
+
+			payload := "USER:42|" + strings.Repeat("x", 1024)
+			c.Set(payload[:7], "Item A")
+			c.SetWeighted("user:43", "Item B", 2)
+
+			val, found := c.Get("user:42")
+			assert.True(t, found)
+			assert.Equal(t, "Item A", val)
+			assert.ElementsMatch(t, []string{"user:42", "user:43"}, c.Keys())
+
+			c.Set("user:44", "Item C")
+			assert.Equal(t, 2, c.Len())
+
+			c.Delete("USER:44")
+			assert.False(t, c.Has("user:44"))
+		})
+	}
+}
+
+// Test `InternKeys` keeps identical keys sharing one copy across garbage collections
+func TestInternKeysShared(t *testing.T) {
+	cfg := cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10, InternKeys: true}
+
+	first := cache.New(&cfg)
+	defer first.Close()
+	first.Set(fmt.Sprintf("user:%d", 42), "Item A")
+
+	runtime.GC()
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+
+	second := cache.New(&cfg)
+	defer second.Close()
+	second.Set(fmt.Sprintf("user:%d", 42), "Item B")
+
+	assert.Same(t, unsafe.StringData(first.Keys()[0]), unsafe.StringData(second.Keys()[0]))
+}
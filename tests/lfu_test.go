@@ -1,9 +1,15 @@
 package tests
 
 import (
+	"fmt"
+	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/hugocarreira/easycache/lfu"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -37,7 +43,285 @@ func (suite *LFUTestSuite) TestLFUEviction() {
 	assert.True(suite.T(), suite.c.Has("C"))
 }
 
+// Test weighted LFU with `SetWeighted()`
+func (suite *LFUTestSuite) TestLFUWeightedEviction() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LFU,
+		MaxSize:        2,
+		LFUScore: func(frequency int, weight float64) float64 {
+			return float64(frequency) * weight
+		},
+	})
+
+	c.SetWeighted("expensive", "Item expensive", 10)
+	c.SetWeighted("cheap", "Item cheap", 1)
+
+	c.Get("cheap")
+	c.Get("cheap")
+
+	c.Set("C", "Item C")
+
+	assert.True(suite.T(), c.Has("expensive"))
+	assert.False(suite.T(), c.Has("cheap"))
+	assert.True(suite.T(), c.Has("C"))
+}
+
+// Test `SetWeighted()` honors weights without `LFUScore`
+func (suite *LFUTestSuite) TestLFUWeightedByDefault() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LFU,
+		MaxSize:        2,
+	})
+
+	c.SetWeighted("heavy", "Item heavy", 100)
+	c.Set("cheap", "Item cheap")
+	c.Get("cheap")
+
+	c.Set("C", "Item C")
+
+	assert.True(suite.T(), c.Has("heavy"))
+	assert.False(suite.T(), c.Has("cheap"))
+	assert.True(suite.T(), c.Has("C"))
+}
+
+// Test switching to weights keeps the frequencies counted so far
+func (suite *LFUTestSuite) TestLFUWeightedKeepsFrequencies() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LFU,
+		MaxSize:        3,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	for i := 0; i < 5; i++ {
+		c.Get("A")
+	}
+	c.Get("B")
+
+	c.SetWeighted("C", "Item C", 2)
+	assert.Equal(suite.T(), []string{"B", "C", "A"}, c.EvictionOrder())
+}
+
+// Test LFU evicts the least recently used among equal frequencies
+func (suite *LFUTestSuite) TestLFUTieBreaking() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LFU,
+		MaxSize:        3,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+
+	c.Set("D", "Item D")
+	assert.False(suite.T(), c.Has("A"))
+
+	c.Set("E", "Item E")
+	assert.False(suite.T(), c.Has("B"))
+
+	assert.True(suite.T(), c.Has("C"))
+	assert.True(suite.T(), c.Has("D"))
+	assert.True(suite.T(), c.Has("E"))
+}
+
+// Test LFU with approximate counters evicts the least frequently used item
+func (suite *LFUTestSuite) TestLFUApproxEviction() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:    cache.LFU,
+		MaxSize:           2,
+		LFUApproxCounters: true,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	c.Get("B")
+	c.Get("A")
+	c.Get("A")
+
+	c.Set("C", "Item C")
+
+	assert.False(suite.T(), c.Has("B"))
+	assert.True(suite.T(), c.Has("A"))
+	assert.True(suite.T(), c.Has("C"))
+}
+
+// Test approximate counters keep the same hot items as exact LFU
+func (suite *LFUTestSuite) TestLFUApproxKeepsHotItems() {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:    cache.LFU,
+		MaxSize:           100,
+		LFUApproxCounters: true,
+	})
+
+	// 50 hot keys accessed repeatedly, then a stream of cold keys
+	for i := 0; i < 10; i++ {
+		for k := 0; k < 50; k++ {
+			c.Set(fmt.Sprintf("hot-%d", k), "value")
+		}
+	}
+	for k := 0; k < 500; k++ {
+		c.Set(fmt.Sprintf("cold-%d", k), "value")
+	}
+
+	for k := 0; k < 50; k++ {
+		assert.True(suite.T(), c.Has(fmt.Sprintf("hot-%d", k)))
+	}
+}
+
+// Test approximate counters reach nearly the hit rate of exact LFU on a skewed workload
+func (suite *LFUTestSuite) TestLFUApproxMatchesExact() {
+	hitRate := func(approx bool) float64 {
+		c := cache.New(&cache.Config{
+			EvictionPolicy:    cache.LFU,
+			MaxSize:           100,
+			LFUApproxCounters: approx,
+		})
+
+		r := rand.New(rand.NewSource(42))
+		zipf := rand.NewZipf(r, 1.1, 1, 999)
+
+		hits := 0
+		for i := 0; i < 20000; i++ {
+			key := fmt.Sprintf("key-%d", zipf.Uint64())
+			if _, found := c.Get(key); found {
+				hits++
+			} else {
+				c.SetIfAbsent(key, "value")
+			}
+		}
+
+		return float64(hits) / 20000
+	}
+
+	exact, approx := hitRate(false), hitRate(true)
+	assert.InDelta(suite.T(), exact, approx, 0.05)
+}
+
 // Run the test suite
+// Test frequencies saturate at `LFUMaxFrequency`, ordering items at the ceiling by recency
+func (suite *LFUTestSuite) TestLFUMaxFrequency() {
+	scores := map[string]func(frequency int, weight float64) float64{
+		"exact":    nil,
+		"weighted": func(frequency int, weight float64) float64 { return float64(frequency) * weight },
+	}
+
+	for name, score := range scores {
+		suite.Run(name, func() {
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  cache.LFU,
+				MaxSize:         3,
+				LFUMaxFrequency: 3,
+				LFUScore:        score,
+			})
+
+			c.Set("A", "Item A")
+			for i := 0; i < 100; i++ {
+				c.Get("A")
+			}
+			c.Set("B", "Item B")
+			for i := 0; i < 100; i++ {
+				c.Get("B")
+			}
+			c.Set("C", "Item C")
+			c.Get("C")
+
+			// A and B are both at the ceiling, so A is evicted before B
+			assert.Equal(suite.T(), []string{"C", "A", "B"}, c.EvictionOrder())
+
+			c.Get("A")
+			assert.Equal(suite.T(), []string{"C", "B", "A"}, c.EvictionOrder())
+
+			c.Set("D", "Item D")
+			assert.False(suite.T(), c.Has("C"))
+		})
+	}
+}
+
+// Test `LFUCountWindow` counts a burst of accesses once per window
+func (suite *LFUTestSuite) TestLFUCountWindow() {
+	variants := map[string]func(cfg *cache.Config){
+		"exact":    func(cfg *cache.Config) {},
+		"weighted": func(cfg *cache.Config) { cfg.LFUScore = lfu.DefaultScore },
+		"approx":   func(cfg *cache.Config) { cfg.LFUApproxCounters = true },
+	}
+
+	for name, variant := range variants {
+		suite.Run(name, func() {
+			clock := newFakeClock()
+			cfg := &cache.Config{
+				EvictionPolicy: cache.LFU,
+				MaxSize:        10,
+				LFUCountWindow: time.Second,
+				Clock:          clock.Now,
+			}
+			variant(cfg)
+			c := cache.New(cfg)
+
+			// A burst right after the insertion doesn't count
+			c.Set("A", "Item A")
+			for i := 0; i < 1000; i++ {
+				c.Get("A")
+			}
+
+			// Bursts over five windows count five times: frequency 6
+			for w := 0; w < 5; w++ {
+				clock.Advance(time.Second)
+				for i := 0; i < 100; i++ {
+					c.Get("A")
+				}
+			}
+
+			// Spaced accesses count every time: frequencies 7, 4 and 1
+			c.Set("B", "Item B")
+			c.Set("D", "Item D")
+			for i := 0; i < 6; i++ {
+				clock.Advance(time.Second)
+				c.Get("B")
+				if i < 3 {
+					c.Get("D")
+				}
+			}
+			c.Set("C", "Item C")
+
+			assert.Equal(suite.T(), []string{"C", "D", "A", "B"}, c.EvictionOrder())
+		})
+	}
+}
+
+// Test the LFU engines are safe for concurrent use on their own
+func (suite *LFUTestSuite) TestLFUEnginesConcurrentAccess() {
+	engines := map[string]engine.Engine{
+		"exact":    lfu.New(0),
+		"weighted": lfu.NewWithScore(0, nil),
+		"approx":   lfu.NewApprox(100),
+	}
+
+	for name, e := range engines {
+		suite.Run(name, func() {
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						key := fmt.Sprintf("key-%d", (g*i)%50)
+						e.Set(key, i)
+						e.Get(key)
+						if i%10 == 0 {
+							e.Evict()
+						}
+						e.Keys()
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			assert.Equal(suite.T(), len(e.Keys()), e.Len())
+		})
+	}
+}
+
 func TestLFUTestSuite(t *testing.T) {
 	suite.Run(t, new(LFUTestSuite))
 }
@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `GetOrSet()` loads a missing key once and caches it
+func TestGetOrSet(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	var calls atomic.Int32
+	load := func() (any, error) {
+		calls.Add(1)
+		return "Item A", nil
+	}
+
+	val, err := c.GetOrSet("A", load)
+	assert.NoError(t, err)
+	assert.Equal(t, "Item A", val)
+
+	val, err = c.GetOrSet("A", load)
+	assert.NoError(t, err)
+	assert.Equal(t, "Item A", val)
+	assert.Equal(t, int32(1), calls.Load())
+
+	failure := errors.New("backend down")
+	_, err = c.GetOrSet("B", func() (any, error) { return nil, failure })
+	assert.ErrorIs(t, err, failure)
+	assert.False(t, c.Has("B"))
+}
+
+// Test `MaxLoadWaiters` makes callers beyond the limit fail fast
+func TestGetOrSetMaxLoadWaiters(t *testing.T) {
+	const waiters = 3
+
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10, MaxLoadWaiters: waiters})
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func() (any, error) {
+		calls.Add(1)
+		close(started)
+		<-release
+		return "Item A", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan any, waiters+1)
+	call := func() {
+		defer wg.Done()
+		val, err := c.GetOrSet("A", load)
+		assert.NoError(t, err)
+		results <- val
+	}
+
+	wg.Add(1)
+	go call()
+	<-started
+
+	wg.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go call()
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// The waiting queue is full: overflow callers don't block
+	for i := 0; i < 5; i++ {
+		_, err := c.GetOrSet("A", load)
+		assert.ErrorIs(t, err, cache.ErrTooManyWaiters)
+	}
+
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for val := range results {
+		assert.Equal(t, "Item A", val)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Once loaded, the value is served from the cache
+	val, err := c.GetOrSet("A", load)
+	assert.NoError(t, err)
+	assert.Equal(t, "Item A", val)
+}
+
+// Test a panicking loader fails the waiting callers instead of reporting success
+func TestGetOrSetLoadPanics(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func() (any, error) {
+		close(started)
+		<-release
+		panic("backend exploded")
+	}
+
+	owner := make(chan any, 1)
+	go func() {
+		defer func() { owner <- recover() }()
+		_, _ = c.GetOrSet("A", load)
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		val, err := c.GetOrSet("A", load)
+		assert.Nil(t, val)
+		waiter <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	close(release)
+	assert.Equal(t, "backend exploded", <-owner)
+	assert.ErrorIs(t, <-waiter, cache.ErrLoadPanicked)
+	assert.False(t, c.Has("A"))
+
+	// The key can be loaded again
+	val, err := c.GetOrSet("A", func() (any, error) { return "Item A", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "Item A", val)
+}
+
+// Test `GetOrRefresh()` serves a stale value while a single refresh runs in the background
+func TestGetOrRefresh(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		StaleGrace:      time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func() (any, error) {
+		calls.Add(1)
+		<-release
+		return "fresh", nil
+	}
+
+	// A missing key is loaded synchronously
+	val, err := c.GetOrRefresh("A", func() (any, error) { return "first", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "first", val)
+
+	// A fresh value doesn't call the loader
+	val, err = c.GetOrRefresh("A", load)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", val)
+	assert.Equal(t, int32(0), calls.Load())
+
+	// Many concurrent stale reads get the stale value and share one refresh
+	clock.Advance(time.Minute + time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := c.GetOrRefresh("A", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "first", val)
+		}()
+	}
+	wg.Wait()
+
+	// A GetOrSet meanwhile waits for the refresh instead of loading again
+	loaded := make(chan any)
+	go func() {
+		val, _ := c.GetOrSet("A", load)
+		loaded <- val
+	}()
+
+	close(release)
+	assert.Equal(t, "fresh", <-loaded)
+	assert.Equal(t, int32(1), calls.Load())
+
+	val, found := c.Get("A")
+	assert.True(t, found)
+	assert.Equal(t, "fresh", val)
+}
+
+// Test a failed `GetOrRefresh()` refresh keeps serving the stale value and is retried
+func TestGetOrRefreshError(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		StaleGrace:      time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("A", "stale")
+	clock.Advance(time.Minute + time.Second)
+
+	var calls atomic.Int32
+	failing := func() (any, error) {
+		calls.Add(1)
+		return nil, errors.New("backend down")
+	}
+
+	// Reads go on serving the stale value, and start a new refresh once the
+	// failed one has finished
+	for deadline := time.Now().Add(time.Second); calls.Load() < 2 && time.Now().Before(deadline); {
+		val, err := c.GetOrRefresh("A", failing)
+		assert.NoError(t, err)
+		assert.Equal(t, "stale", val)
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, found := c.Get("A")
+	assert.False(t, found)
+}
+
+// Test a panicking background refresh is reported to `OnCallbackPanic`
+func TestGetOrRefreshPanicRecovered(t *testing.T) {
+	clock := newFakeClock()
+	recovered := make(chan any, 1)
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		StaleGrace:      time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+		OnCallbackPanic: func(value any) { recovered <- value },
+	})
+	defer c.Close()
+
+	c.Set("A", "stale")
+	clock.Advance(time.Minute + time.Second)
+
+	val, err := c.GetOrRefresh("A", func() (any, error) { panic("backend exploded") })
+	assert.NoError(t, err)
+	assert.Equal(t, "stale", val)
+
+	select {
+	case value := <-recovered:
+		assert.Equal(t, "backend exploded", value)
+	case <-time.After(time.Second):
+		t.Fatal("the panic of the refresh was not reported")
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// LRUKTestSuite defines the test structure
+type LRUKTestSuite struct {
+	suite.Suite
+	c *cache.Cache
+}
+
+// Setup before each test
+func (suite *LRUKTestSuite) SetupTest() {
+	suite.c = cache.New(&cache.Config{
+		EvictionPolicy: cache.LRUK,
+		MaxSize:        3,
+	})
+}
+
+// Test LRU-K keeps a frequently accessed key over scan keys
+func (suite *LRUKTestSuite) TestLRUKScanResistance() {
+	suite.c.Set("hot", "Item hot")
+	suite.c.Get("hot")
+
+	// A scan touches each key only once
+	suite.c.Set("scan1", "Item scan1")
+	suite.c.Set("scan2", "Item scan2")
+	suite.c.Set("scan3", "Item scan3")
+	suite.c.Set("scan4", "Item scan4")
+
+	assert.True(suite.T(), suite.c.Has("hot"))
+	assert.False(suite.T(), suite.c.Has("scan1"))
+	assert.False(suite.T(), suite.c.Has("scan2"))
+	assert.True(suite.T(), suite.c.Has("scan3"))
+	assert.True(suite.T(), suite.c.Has("scan4"))
+}
+
+// Test LRU-K evicts the oldest K-th reference among fully referenced keys
+func (suite *LRUKTestSuite) TestLRUKEviction() {
+	suite.c.Set("A", "Item A")
+	suite.c.Set("B", "Item B")
+	suite.c.Set("C", "Item C")
+
+	suite.c.Get("B")
+	suite.c.Get("C")
+	suite.c.Get("A")
+
+	suite.c.Set("D", "Item D")
+	suite.c.Get("D")
+
+	// A's second most recent access is older than B's and C's
+	assert.False(suite.T(), suite.c.Has("A"))
+	assert.True(suite.T(), suite.c.Has("B"))
+	assert.True(suite.T(), suite.c.Has("C"))
+	assert.True(suite.T(), suite.c.Has("D"))
+}
+
+// Run the test suite
+func TestLRUKTestSuite(t *testing.T) {
+	suite.Run(t, new(LRUKTestSuite))
+}
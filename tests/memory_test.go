@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test memory pressure evicts until usage drops below the limit
+func TestMemoryPressureEvictsUntilBelowLimit(t *testing.T) {
+	const itemBytes = 1000
+
+	// Within a check, every read of the usage but the first follows an
+	// eviction, so a read with no eviction since the previous one starts a
+	// new check
+	var (
+		c             *cache.Cache
+		checks        atomic.Int32
+		lastEvictions int64
+	)
+	c = cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             100,
+		Metrics:             true,
+		MemoryLimits:        5 * itemBytes,
+		MemoryCheckInterval: 200 * time.Millisecond,
+		MemoryUsage: func() uint64 {
+			evictions := c.Metrics().Evictions()
+			if evictions == lastEvictions {
+				checks.Add(1)
+			}
+			lastEvictions = evictions
+			return uint64(20-evictions) * itemBytes
+		},
+	})
+	defer c.Close()
+
+	for i := 0; i < 20; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+
+	assert.Eventually(t, func() bool {
+		return c.Len() <= 5
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// A single check evicts all the excess, not one item per tick
+	assert.Equal(t, int32(1), checks.Load())
+	assert.Greater(t, c.Len(), 0)
+	assert.GreaterOrEqual(t, c.Metrics().Evictions(), int64(15))
+}
+
+// Test memory watermarks evict down to the low mark and don't flap at the high mark
+func TestMemoryWatermarksHysteresis(t *testing.T) {
+	const itemBytes = 1000
+
+	var (
+		c      *cache.Cache
+		stored atomic.Int64
+	)
+	c = cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             100,
+		Metrics:             true,
+		MemoryHighWatermark: 10 * itemBytes,
+		MemoryLowWatermark:  5 * itemBytes,
+		MemoryCheckInterval: 10 * time.Millisecond,
+		MemoryUsage: func() uint64 {
+			return uint64(stored.Load()-c.Metrics().Evictions()) * itemBytes
+		},
+	})
+	defer c.Close()
+
+	set := func(i int) {
+		stored.Add(1)
+		c.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+
+	// Crossing the high mark evicts down to the low mark at once
+	for i := 0; i < 11; i++ {
+		set(i)
+	}
+	assert.Eventually(t, func() bool {
+		return c.Len() <= 5
+	}, time.Second, 5*time.Millisecond)
+
+	// Usage oscillating up to the high mark, without exceeding it, evicts nothing
+	next := 11
+	for round := 0; round < 5; round++ {
+		evictions := c.Metrics().Evictions()
+		for c.Len() < 10 {
+			set(next)
+			next++
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, evictions, c.Metrics().Evictions())
+		assert.Equal(t, 10, c.Len())
+
+		c.Delete(fmt.Sprintf("key-%d", next-1))
+		stored.Add(-1)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, evictions, c.Metrics().Evictions())
+	}
+
+	// Crossing it again goes back down to the low mark, not just below the high one
+	set(next)
+	set(next + 1)
+	assert.Eventually(t, func() bool {
+		return c.Len() <= 5
+	}, time.Second, 5*time.Millisecond)
+}
+
+// Test caches without memory limits don't start a memory check goroutine
+func TestNoMemoryGoroutineWithoutLimits(t *testing.T) {
+	const caches = 1000
+
+	// Goroutines of caches closed by earlier tests may still be exiting
+	assert.True(t, waitMemoryCheckGoroutines(0))
+
+	created := make([]*cache.Cache, 0, caches)
+	for i := 0; i < caches; i++ {
+		created = append(created, cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10}))
+	}
+	assert.Equal(t, 0, memoryCheckGoroutines())
+
+	for _, c := range created {
+		c.Close()
+	}
+
+	// With limits, the check runs until Close. The goroutines are polled
+	// without assert.Eventually, which runs the condition in a goroutine.
+	c := cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             10,
+		MemoryLimits:        1 << 30,
+		MemoryCheckInterval: time.Hour,
+	})
+	assert.True(t, waitMemoryCheckGoroutines(1))
+
+	c.Close()
+	assert.True(t, waitMemoryCheckGoroutines(0))
+}
+
+// memoryCheckGoroutines returns the number of running memory check
+// goroutines. They are found by their stack, so goroutines left over by other
+// tests don't skew the count.
+func memoryCheckGoroutines() int {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return strings.Count(string(buf[:n]), "startCheckMemoryUsage")
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// waitMemoryCheckGoroutines polls for up to a second, until exactly want
+// memory check goroutines are running. It reports whether that happened.
+func waitMemoryCheckGoroutines(want int) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if memoryCheckGoroutines() == want {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+// Test `MemoryEvictLargest` evicts the largest entries under memory pressure
+func TestMemoryEvictLargest(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             100,
+		EstimateSize:        true,
+		MemoryLimits:        1000,
+		MemoryCheckInterval: 10 * time.Millisecond,
+		MemoryEviction:      cache.MemoryEvictLargest,
+	})
+	defer c.Close()
+
+	// The small entries are the least recently used, so LRU would evict them first
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprintf("s%d", i), strings.Repeat("x", 10))
+	}
+	for i := 0; i < 4; i++ {
+		c.Set(fmt.Sprintf("b%d", i), strings.Repeat("x", 1000))
+	}
+
+	assert.Eventually(t, func() bool {
+		return c.EstimatedBytes() <= 1000
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 10, c.Len())
+	for i := 0; i < 10; i++ {
+		assert.True(t, c.Has(fmt.Sprintf("s%d", i)))
+	}
+}
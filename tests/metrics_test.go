@@ -0,0 +1,373 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Snapshot()` counts
+func TestMetricsSnapshot(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        1,
+		Metrics:        true,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("B")
+	c.Get("A")
+
+	snapshot := c.Metrics().Snapshot()
+	assert.Equal(t, c.Metrics().Hits(), snapshot.Hits)
+	assert.Equal(t, int64(1), snapshot.Misses)
+	assert.Equal(t, int64(1), snapshot.Evictions)
+	assert.Equal(t, c.Metrics().HitRate(), snapshot.HitRate)
+}
+
+// Test a read of an expired key counts as an expiration, not a miss
+func TestMetricsExpirations(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  policy,
+				TTL:             time.Minute,
+				CleanupInterval: time.Hour,
+				Clock:           clock.Now,
+				Metrics:         true,
+			})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			c.Get("A")
+			clock.Advance(2 * time.Minute)
+
+			c.Get("unknown")
+			c.Get("A")
+
+			snapshot := c.Metrics().Snapshot()
+			assert.Equal(t, int64(1), snapshot.Misses)
+			assert.Equal(t, int64(1), snapshot.Expirations)
+			assert.Equal(t, int64(1), c.Metrics().Expirations())
+			total := snapshot.Hits + snapshot.Misses + snapshot.Expirations
+			assert.InDelta(t, float64(snapshot.Hits)/float64(total), snapshot.HitRate, 1e-9)
+			assert.Equal(t, snapshot.Expirations, c.Stats().Expirations)
+		})
+	}
+}
+
+// Test `Snapshot()` consistency under concurrent increments
+func TestMetricsSnapshotConcurrent(t *testing.T) {
+	m := cache.NewMetrics()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.IncrementHits()
+				m.IncrementMisses()
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		s := m.Snapshot()
+		if s.Hits+s.Misses == 0 {
+			assert.Equal(t, float64(0), s.HitRate)
+			continue
+		}
+		assert.InDelta(t, float64(s.Hits)/float64(s.Hits+s.Misses), s.HitRate, 1e-9)
+		assert.InDelta(t, 1, s.HitRate+s.MissRate, 1e-9)
+	}
+	wg.Wait()
+
+	s := m.Snapshot()
+	assert.Equal(t, int64(8000), s.Hits)
+	assert.Equal(t, int64(8000), s.Misses)
+	assert.Equal(t, 0.5, s.HitRate)
+}
+
+// Test `GetLatency()` and `SetLatency()` percentiles
+func TestLatencyMetrics(t *testing.T) {
+	// LRU-K reads no clock of its own, so every call takes exactly one step
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRUK,
+		MaxSize:        10,
+		LatencyMetrics: true,
+		Clock:          clock.Now,
+	})
+
+	clock.SetStep(2 * time.Millisecond)
+	c.Set("A", "Item A")
+
+	for _, tc := range []struct {
+		step  time.Duration
+		times int
+	}{
+		{time.Millisecond, 900},
+		{10 * time.Millisecond, 90},
+		{100 * time.Millisecond, 10},
+	} {
+		clock.SetStep(tc.step)
+		for i := 0; i < tc.times; i++ {
+			c.Get("A")
+		}
+	}
+
+	get := c.Metrics().GetLatency()
+	assert.Equal(t, int64(1000), get.Count)
+	assert.InEpsilon(t, float64(time.Millisecond), float64(get.P50), 0.125)
+	assert.InEpsilon(t, float64(time.Millisecond), float64(get.P90), 0.125)
+	assert.InEpsilon(t, float64(10*time.Millisecond), float64(get.P99), 0.125)
+	assert.InEpsilon(t, float64(100*time.Millisecond), float64(get.P999), 0.125)
+
+	set := c.Metrics().SetLatency()
+	assert.Equal(t, int64(1), set.Count)
+	assert.InEpsilon(t, float64(2*time.Millisecond), float64(set.P50), 0.125)
+}
+
+// Test latencies are not recorded when disabled
+func TestLatencyMetricsDisabled(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	c.Set("A", "Item A")
+	c.Get("A")
+
+	assert.Equal(t, int64(0), c.Metrics().GetLatency().Count)
+	assert.Equal(t, time.Duration(0), c.Metrics().GetLatency().P99)
+}
+
+// Test recording known durations directly
+func TestRecordLatency(t *testing.T) {
+	m := cache.NewMetrics()
+
+	for i := 1; i <= 100; i++ {
+		m.RecordGetLatency(time.Duration(i) * time.Microsecond)
+	}
+
+	latency := m.GetLatency()
+	assert.InEpsilon(t, float64(50*time.Microsecond), float64(latency.P50), 0.125)
+	assert.InEpsilon(t, float64(90*time.Microsecond), float64(latency.P90), 0.125)
+	assert.InEpsilon(t, float64(99*time.Microsecond), float64(latency.P99), 0.125)
+}
+
+// Test `ResetMetrics()`
+func TestResetMetrics(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        1,
+		Metrics:        true,
+		LatencyMetrics: true,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("B")
+	c.Get("A")
+
+	assert.NotZero(t, c.Metrics().Hits())
+	assert.NotZero(t, c.Metrics().Misses())
+	assert.NotZero(t, c.Metrics().Evictions())
+
+	c.ResetMetrics()
+
+	snapshot := c.Metrics().Snapshot()
+	assert.Equal(t, int64(0), snapshot.Hits)
+	assert.Equal(t, int64(0), snapshot.Misses)
+	assert.Equal(t, int64(0), snapshot.Evictions)
+	assert.Equal(t, float64(0), snapshot.HitRate)
+	assert.Equal(t, float64(0), snapshot.MissRate)
+	assert.Equal(t, float64(0), c.Metrics().MissRate())
+	assert.Equal(t, int64(0), c.Metrics().GetLatency().Count)
+
+	c.Get("B")
+	assert.Equal(t, float64(1), c.Metrics().HitRate())
+}
+
+// Test `EnableMetrics()` only counts while enabled
+func TestEnableMetrics(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+	})
+	assert.False(t, c.MetricsEnabled())
+
+	c.Set("A", "Item A")
+	c.Get("A")
+	c.Get("B")
+	assert.Equal(t, int64(0), c.Metrics().Hits())
+	assert.Equal(t, int64(0), c.Metrics().Misses())
+
+	c.EnableMetrics(true)
+	assert.True(t, c.MetricsEnabled())
+	c.Get("A")
+	c.Get("B")
+	assert.Equal(t, int64(1), c.Metrics().Hits())
+	assert.Equal(t, int64(1), c.Metrics().Misses())
+
+	c.EnableMetrics(false)
+	c.Get("A")
+	c.Get("B")
+	assert.Equal(t, int64(1), c.Metrics().Hits())
+	assert.Equal(t, int64(1), c.Metrics().Misses())
+}
+
+// Test `Stats()` after a known sequence of operations
+func TestStats(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:      cache.LRU,
+		MaxSize:             2,
+		Metrics:             true,
+		MemoryLimits:        1 << 30,
+		MemoryCheckInterval: time.Hour,
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+	c.Get("A")
+	c.Get("C")
+
+	stats := c.Stats()
+	assert.Equal(t, cache.LRU, stats.Policy)
+	assert.Equal(t, 2, stats.MaxSize)
+	assert.Equal(t, 2, stats.Len)
+	assert.Equal(t, int64(4), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.InDelta(t, 0.8, stats.HitRate, 1e-9)
+	assert.Equal(t, uint64(1<<30), stats.MemoryLimits)
+	assert.Equal(t, time.Hour, stats.MemoryCheckInterval)
+}
+
+// Test `MetricsLogger` is called periodically until `Close()`
+func TestMetricsLogger(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		logged []cache.Stats
+	)
+	c := cache.New(&cache.Config{
+		EvictionPolicy:     cache.LRU,
+		MaxSize:            10,
+		Metrics:            true,
+		MetricsLogInterval: 10 * time.Millisecond,
+		MetricsLogger: func(stats cache.Stats) {
+			lock.Lock()
+			defer lock.Unlock()
+			logged = append(logged, stats)
+		},
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("A")
+	c.Get("missing")
+
+	count := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(logged)
+	}
+	assert.Eventually(t, func() bool { return count() >= 2 }, time.Second, 5*time.Millisecond)
+
+	lock.Lock()
+	last := logged[len(logged)-1]
+	lock.Unlock()
+	assert.Equal(t, cache.LRU, last.Policy)
+	assert.Equal(t, 2, last.Len)
+	assert.Equal(t, int64(3), last.Hits)
+	assert.Equal(t, int64(1), last.Misses)
+
+	c.Close()
+	time.Sleep(20 * time.Millisecond)
+	stopped := count()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, count())
+}
+
+// Test `EvictionRegret()` counts misses on recently evicted keys
+func TestMetricsEvictionRegret(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 2, Metrics: true})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C") // Evicts A
+
+	c.Get("missing")
+	assert.Equal(t, int64(0), c.Metrics().EvictionRegret())
+
+	_, found := c.Get("A")
+	assert.False(t, found)
+	assert.Equal(t, int64(1), c.Metrics().EvictionRegret())
+	assert.Equal(t, int64(1), c.Metrics().Snapshot().EvictionRegret)
+
+	// Each eviction is regretted once
+	c.Get("A")
+	assert.Equal(t, int64(1), c.Metrics().EvictionRegret())
+
+	// A key stored again is no longer a ghost
+	c.Set("A", "Item A") // Evicts B
+	c.Delete("A")
+	c.Get("A")
+	assert.Equal(t, int64(1), c.Metrics().EvictionRegret())
+
+	c.Get("B")
+	assert.Equal(t, int64(2), c.Metrics().EvictionRegret())
+}
+
+// Test the ghost list only remembers the last `EvictionGhostSize` evicted keys
+func TestMetricsEvictionRegretBounded(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 1, EvictionGhostSize: 2, Metrics: true})
+
+	for _, key := range []string{"A", "B", "C", "D"} {
+		c.Set(key, "value") // Evicts A, B and C in turn
+	}
+
+	c.Get("A")
+	assert.Equal(t, int64(0), c.Metrics().EvictionRegret())
+	c.Get("B")
+	c.Get("C")
+	assert.Equal(t, int64(2), c.Metrics().EvictionRegret())
+}
+
+// Test `LastEviction()` and `EvictionsPerMinute()` follow evictions over time
+func TestMetricsEvictionRate(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 1, Metrics: true, Clock: clock.Now})
+
+	assert.True(t, c.Metrics().LastEviction().IsZero())
+	assert.Equal(t, int64(0), c.Metrics().EvictionsPerMinute())
+
+	start := clock.Now()
+	for i := 0; i < 4; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i) // Evicts the previous key
+	}
+	assert.Equal(t, int64(3), c.Metrics().EvictionsPerMinute())
+	assert.True(t, start.Equal(c.Metrics().LastEviction()))
+
+	clock.Advance(30 * time.Second)
+	c.Set("key-4", 4)
+	assert.Equal(t, int64(4), c.Metrics().EvictionsPerMinute())
+	assert.True(t, start.Add(30*time.Second).Equal(c.Metrics().LastEviction()))
+
+	// The first evictions leave the window, the last one is still in it
+	clock.Advance(45 * time.Second)
+	assert.Equal(t, int64(1), c.Metrics().EvictionsPerMinute())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, int64(0), c.Metrics().EvictionsPerMinute())
+	assert.Equal(t, int64(4), c.Metrics().Evictions())
+
+	c.ResetMetrics()
+	assert.True(t, c.Metrics().LastEviction().IsZero())
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test namespaces store the same logical key separately
+func TestNamespace(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	users, orders := c.Namespace("users"), c.Namespace("orders")
+
+	users.Set("42", "Alice")
+	orders.Set("42", "Order 42")
+	c.Set("42", "global")
+
+	val, found := users.Get("42")
+	assert.True(t, found)
+	assert.Equal(t, "Alice", val)
+
+	val, found = orders.Get("42")
+	assert.True(t, found)
+	assert.Equal(t, "Order 42", val)
+
+	_, found = users.Get("7")
+	assert.False(t, found)
+
+	assert.Equal(t, []string{"42"}, users.Keys())
+	assert.Equal(t, 3, c.Len())
+
+	users.Delete("42")
+	assert.False(t, users.Has("42"))
+	assert.True(t, orders.Has("42"))
+}
+
+// Test `Clear()` and `DeletePrefix()` are scoped to the namespace
+func TestNamespaceClear(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	user, users := c.Namespace("user"), c.Namespace("users")
+
+	user.Set("session:1", "a")
+	user.Set("session:2", "b")
+	user.Set("profile", "c")
+	users.Set("session:1", "d")
+
+	assert.Equal(t, 2, user.DeletePrefix("session:"))
+	assert.Equal(t, []string{"profile"}, user.Keys())
+	assert.True(t, users.Has("session:1"))
+
+	user.Clear()
+	assert.Empty(t, user.Keys())
+	assert.Equal(t, []string{"session:1"}, users.Keys())
+	assert.Equal(t, 1, c.Len())
+}
+
+// Test `DeletePrefix()` on the whole cache
+func TestDeletePrefix(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 10})
+	c.Set("tmp:1", "a")
+	c.Set("tmp:2", "b")
+	c.Set("keep", "c")
+
+	assert.Equal(t, 2, c.DeletePrefix("tmp:"))
+	assert.Equal(t, []string{"keep"}, c.Keys())
+}
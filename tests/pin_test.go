@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test pinned keys are never evicted when the cache fills past capacity
+func TestPin(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 3})
+			defer c.Close()
+
+			c.Pin("config")
+			c.Set("config", "value")
+			assert.True(t, c.IsPinned("config"))
+
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				c.Set(key, i)
+				c.Get(key)
+				c.Get(key)
+
+				assert.True(t, c.Has("config"), "pinned key evicted at %s", key)
+				assert.LessOrEqual(t, c.Len(), 3)
+			}
+
+			// Once unpinned, the key can be evicted again
+			c.Unpin("config")
+			assert.False(t, c.IsPinned("config"))
+			for i := 0; i < 20; i++ {
+				c.Set(fmt.Sprintf("other-%d", i), i)
+			}
+			assert.False(t, c.Has("config"))
+		})
+	}
+}
+
+// Test eviction stops when every key is pinned
+func TestPinAll(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 2})
+
+	c.Pin("A")
+	c.Pin("B")
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	_, evicted := c.EvictKey()
+	assert.False(t, evicted)
+
+	// The cache grows past MaxSize rather than evicting a pinned key
+	c.Set("C", "Item C")
+	assert.Equal(t, 3, c.Len())
+
+	key, evicted := c.EvictKey()
+	assert.True(t, evicted)
+	assert.Equal(t, "C", key)
+	assert.True(t, c.Has("A"))
+	assert.True(t, c.Has("B"))
+}
+
+// Test pins keep the second chance of the Clock policy: skipped keys don't
+// spare the referenced ones for good
+func TestPinClockSecondChance(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Clock, MaxSize: 3})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+	c.Get("A")
+	c.Pin("B")
+
+	// A gets a second chance, and B is skipped
+	c.Set("D", "Item D")
+	assert.False(t, c.Has("C"))
+	assert.True(t, c.Has("A"))
+
+	// A used its second chance, so it goes before the newer D
+	c.Set("E", "Item E")
+	assert.False(t, c.Has("A"))
+	assert.True(t, c.Has("B"))
+	assert.True(t, c.Has("D"))
+}
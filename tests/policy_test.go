@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `ParseEvictionPolicy()` with valid names
+func TestParseEvictionPolicy(t *testing.T) {
+	for _, policy := range allPolicies {
+		parsed, err := cache.ParseEvictionPolicy(policy.String())
+		assert.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+
+	for name, expected := range map[string]cache.EvictionPolicy{
+		"LRU":    cache.LRU,
+		"Lfu":    cache.LFU,
+		" fifo ": cache.FIFO,
+		"BASIC":  cache.Basic,
+		"LruK":   cache.LRUK,
+		"CLOCK":  cache.Clock,
+	} {
+		parsed, err := cache.ParseEvictionPolicy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, parsed)
+	}
+}
+
+// Test `ParseEvictionPolicy()` with unknown names
+func TestParseEvictionPolicyUnknown(t *testing.T) {
+	for _, name := range []string{"", "mru", "unknown", "lru2"} {
+		_, err := cache.ParseEvictionPolicy(name)
+		assert.Error(t, err)
+	}
+}
+
+// Test loading the policy by name from a text config
+func TestEvictionPolicyText(t *testing.T) {
+	var cfg struct {
+		Policy cache.EvictionPolicy `json:"policy"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"policy":"LFU"}`), &cfg))
+	assert.Equal(t, cache.LFU, cfg.Policy)
+
+	out, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"policy":"lfu"}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"policy":"nope"}`), &cfg))
+}
+
+// Test `SwitchPolicy()` keeps every key while the cache is in use
+func TestSwitchPolicy(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        1000,
+	})
+
+	for i := 0; i < 500; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c.Get(fmt.Sprintf("key-%d", i))
+			}
+		}()
+	}
+
+	assert.NoError(t, c.SwitchPolicy(cache.LRU))
+	wg.Wait()
+
+	assert.Equal(t, cache.LRU, c.Policy())
+	assert.Equal(t, 500, c.Len())
+	for i := 0; i < 500; i++ {
+		val, found := c.Get(fmt.Sprintf("key-%d", i))
+		assert.True(t, found)
+		assert.Equal(t, i, val)
+	}
+
+	// The new policy applies from now on
+	c.Get("key-0")
+	assert.Equal(t, "key-1", c.EvictionOrder()[0])
+}
+
+// Test `SwitchPolicy()` keeps the recency order and the remaining TTLs
+func TestSwitchPolicyKeepsOrderAndTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.LRU,
+		MaxSize:         3,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("A")
+
+	assert.NoError(t, c.SwitchPolicy(cache.FIFO))
+	assert.Equal(t, []string{"B", "A"}, c.EvictionOrder())
+
+	assert.NoError(t, c.SwitchPolicy(cache.Basic))
+	c.SetWithDeadline("short", "Item short", clock.Now().Add(time.Minute))
+
+	assert.NoError(t, c.SwitchPolicy(cache.Basic))
+	clock.Advance(2 * time.Minute)
+	assert.False(t, c.Has("short"))
+	assert.True(t, c.Has("A"))
+	assert.True(t, c.Has("B"))
+
+	assert.ErrorIs(t, c.SwitchPolicy(cache.EvictionPolicy(42)), cache.ErrInvalidConfig)
+
+	c.Close()
+	assert.ErrorIs(t, c.SwitchPolicy(cache.LRU), cache.ErrClosed)
+}
+
+// Test `Policy()` and `IsExpirable()` report the configured policy
+func TestPolicyMatchesConfig(t *testing.T) {
+	expirable := map[cache.EvictionPolicy]bool{cache.Basic: true, cache.FIFO: true}
+
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			cfg := &cache.Config{EvictionPolicy: policy, MaxSize: 10, TTL: time.Minute}
+
+			c := cache.New(cfg)
+			defer c.Close()
+			assert.Equal(t, policy, c.Policy())
+			assert.Equal(t, expirable[policy], c.IsExpirable())
+
+			c2, err := cache.NewWithError(cfg)
+			assert.NoError(t, err)
+			defer c2.Close()
+			assert.Equal(t, policy, c2.Policy())
+			assert.Equal(t, expirable[policy], c2.IsExpirable())
+		})
+	}
+
+	// Without a Config the cache uses the Basic policy
+	c := cache.New(nil)
+	defer c.Close()
+	assert.Equal(t, cache.Basic, c.Policy())
+	assert.True(t, c.IsExpirable())
+}
+
+// Test `IsExpirable()` follows `SwitchPolicy()`
+func TestIsExpirableAfterSwitchPolicy(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 10, TTL: time.Minute})
+	defer c.Close()
+	assert.True(t, c.IsExpirable())
+
+	assert.NoError(t, c.SwitchPolicy(cache.LRU))
+	assert.Equal(t, cache.LRU, c.Policy())
+	assert.False(t, c.IsExpirable())
+}
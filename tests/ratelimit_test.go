@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Allow()` denies requests over the limit until the window ends
+func TestRateLimitAllow(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Basic, Clock: clock.Now})
+	defer c.Close()
+
+	limiter := ratelimit.NewWithOptions(c, ratelimit.Options{Clock: clock.Now})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow("client", 3, time.Minute), "request %d", i+1)
+	}
+	assert.False(t, limiter.Allow("client", 3, time.Minute))
+	assert.False(t, limiter.Allow("client", 3, time.Minute))
+
+	// Other keys have their own counter
+	assert.True(t, limiter.Allow("other", 3, time.Minute))
+
+	// The counter resets, and expires from the cache, once the window ends
+	clock.Advance(time.Minute + time.Second)
+	assert.Equal(t, 0, c.Len())
+	assert.True(t, limiter.Allow("client", 3, time.Minute))
+}
+
+// Test `Allow()` with invalid limits and windows
+func TestRateLimitInvalid(t *testing.T) {
+	limiter := ratelimit.New(cache.New(&cache.Config{EvictionPolicy: cache.Basic}))
+
+	assert.False(t, limiter.Allow("client", 0, time.Minute))
+	assert.False(t, limiter.Allow("client", 1, 0))
+}
+
+// Test limiters sharing a cache never let more requests through than the limit
+func TestRateLimitShared(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Basic})
+	defer c.Close()
+
+	limiters := []*ratelimit.Limiter{ratelimit.New(c), ratelimit.New(c)}
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int32
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiters[i%2].Allow("client", 10, time.Hour) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), allowed.Load())
+}
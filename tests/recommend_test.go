@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test representative workloads map to the expected policy
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload cache.WorkloadProfile
+		expected cache.EvictionPolicy
+	}{
+		{"session store", cache.WorkloadProfile{ReadRatio: 0.8, Expiring: true}, cache.Basic},
+		{"rate limit windows", cache.WorkloadProfile{ReadRatio: 0.3, Expiring: true}, cache.FIFO},
+		{"analytics with scans", cache.WorkloadProfile{ReadRatio: 0.95, Scans: true}, cache.LRUK},
+		{"small key space", cache.WorkloadProfile{ReadRatio: 0.7, KeyCardinality: 100, MaxSize: 1000}, cache.FIFO},
+		{"write-heavy log buffer", cache.WorkloadProfile{ReadRatio: 0.2}, cache.FIFO},
+		{"read-heavy catalog", cache.WorkloadProfile{ReadRatio: 0.99, KeyCardinality: 1000000, MaxSize: 10000}, cache.LFU},
+		{"mixed web cache", cache.WorkloadProfile{ReadRatio: 0.7}, cache.LRU},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, rationale := cache.RecommendWithRationale(tt.workload)
+			assert.Equal(t, tt.expected, policy)
+			assert.NotEmpty(t, rationale)
+			assert.Equal(t, policy, cache.Recommend(tt.workload))
+		})
+	}
+}
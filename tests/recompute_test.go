@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// earlyMissRate returns the fraction of Get calls reporting a miss on a key
+// computed in computeTime and expiring in remaining
+func earlyMissRate(computeTime, remaining time.Duration) float64 {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+		EarlyRecompute:  1,
+	})
+	defer c.Close()
+
+	c.SetWithComputeTime("A", "Item A", computeTime)
+	clock.Advance(time.Minute - remaining)
+
+	misses := 0
+	for i := 0; i < 10000; i++ {
+		if _, found := c.Get("A"); !found {
+			misses++
+		}
+	}
+
+	return float64(misses) / 10000
+}
+
+// Test near-expiry Get calls recompute for the expected fraction of callers
+func TestEarlyRecompute(t *testing.T) {
+	// P(miss) = exp(-remaining / (computeTime * EarlyRecompute))
+	assert.InDelta(t, math.Exp(-1), earlyMissRate(time.Second, time.Second), 0.03)
+	assert.InDelta(t, math.Exp(-0.5), earlyMissRate(2*time.Second, time.Second), 0.03)
+
+	// Far from the expiration, the value is always served
+	assert.Equal(t, float64(0), earlyMissRate(time.Millisecond, 50*time.Second))
+}
+
+// Test the value is served without `EarlyRecompute` or a compute time
+func TestEarlyRecomputeDisabled(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.SetWithComputeTime("A", "Item A", time.Minute)
+	clock.Advance(59 * time.Second)
+
+	for i := 0; i < 100; i++ {
+		val, found := c.Get("A")
+		assert.True(t, found)
+		assert.Equal(t, "Item A", val)
+	}
+}
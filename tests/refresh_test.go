@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `RegisterRefresher()` reloads a key before it expires, so reads never miss
+func TestRegisterRefresher(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:   cache.Basic,
+		TTL:              200 * time.Millisecond,
+		RefreshAheadLead: 100 * time.Millisecond,
+		CleanupInterval:  10 * time.Millisecond,
+		Metrics:          true,
+	})
+
+	var loads atomic.Int32
+	c.RegisterRefresher("A", func() (any, error) {
+		return int(loads.Add(1)), nil
+	})
+
+	// The missing key is loaded right away
+	assert.Eventually(t, func() bool {
+		return c.Has("A")
+	}, time.Second, time.Millisecond)
+
+	// Reads over several TTLs always hit
+	for start := time.Now(); time.Since(start) < 700*time.Millisecond; {
+		_, found := c.Get("A")
+		assert.True(t, found)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int64(0), c.Metrics().Misses())
+	assert.GreaterOrEqual(t, loads.Load(), int32(4))
+
+	val, _ := c.Get("A")
+	assert.Greater(t, val, 1)
+
+	// Close stops the refresher
+	c.Close()
+	time.Sleep(20 * time.Millisecond)
+	after := loads.Load()
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, after, loads.Load())
+}
+
+// Test `UnregisterRefresher()` lets the key expire, and failed refreshes are retried
+func TestUnregisterRefresher(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:   cache.Basic,
+		TTL:              100 * time.Millisecond,
+		RefreshAheadLead: 50 * time.Millisecond,
+		CleanupInterval:  10 * time.Millisecond,
+	})
+	defer c.Close()
+
+	c.Set("A", "initial")
+
+	var calls atomic.Int32
+	c.RegisterRefresher("A", func() (any, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("backend down")
+		}
+		return "refreshed", nil
+	})
+
+	assert.Eventually(t, func() bool {
+		val, _ := c.Get("A")
+		return val == "refreshed"
+	}, time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, calls.Load(), int32(2))
+
+	c.UnregisterRefresher("A")
+	assert.Eventually(t, func() bool {
+		return !c.Has("A")
+	}, time.Second, 5*time.Millisecond)
+}
+
+// Test the refresher of a key that never expires reloads it once it is gone
+func TestRefresherReloadsNeverExpiringKey(t *testing.T) {
+	recovered := make(chan any, 1)
+	c := cache.New(&cache.Config{
+		EvictionPolicy:   cache.LRU,
+		MaxSize:          10,
+		RefreshAheadLead: 20 * time.Millisecond,
+		OnCallbackPanic:  func(value any) { recovered <- value },
+	})
+	defer c.Close()
+
+	var calls atomic.Int32
+	c.RegisterRefresher("A", func() (any, error) {
+		if calls.Add(1) == 2 {
+			panic("backend exploded")
+		}
+		return "Item A", nil
+	})
+
+	assert.Eventually(t, func() bool {
+		return c.Has("A")
+	}, time.Second, time.Millisecond)
+
+	// The panicking reload is reported and retried
+	c.Delete("A")
+	select {
+	case value := <-recovered:
+		assert.Equal(t, "backend exploded", value)
+	case <-time.After(time.Second):
+		t.Fatal("the panic of the refresh was not reported")
+	}
+
+	assert.Eventually(t, func() bool {
+		return c.Has("A")
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `Scan()` visits every key in pages across policies
+func TestScan(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        100,
+				TTL:            time.Minute,
+			})
+			for i := 0; i < 25; i++ {
+				c.Set(fmt.Sprintf("key-%02d", i), "value")
+			}
+
+			keys, cursor := c.Scan(10)
+			assert.Len(t, keys, 10)
+			assert.False(t, cursor.Done())
+
+			visited := keys
+			for !cursor.Done() {
+				keys, cursor = cursor.Next(10)
+				visited = append(visited, keys...)
+			}
+
+			assert.Len(t, visited, 25)
+			assert.ElementsMatch(t, c.Keys(), visited)
+
+			keys, _ = cursor.Next(10)
+			assert.Empty(t, keys)
+		})
+	}
+}
+
+// Test `Scan()` visits keys present for the whole scan despite concurrent writes
+func TestScanConcurrentMutations(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        1000,
+	})
+	for i := 0; i < 200; i++ {
+		c.Set(fmt.Sprintf("stable-%03d", i), "value")
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			key := fmt.Sprintf("churn-%03d", i%300)
+			c.Set(key, "value")
+			c.Delete(fmt.Sprintf("churn-%03d", (i+150)%300))
+		}
+	}()
+
+	seen := make(map[string]int)
+	keys, cursor := c.Scan(7)
+	for {
+		for _, key := range keys {
+			seen[key]++
+		}
+		if cursor.Done() {
+			break
+		}
+		keys, cursor = cursor.Next(7)
+	}
+	close(done)
+	wg.Wait()
+
+	for i := 0; i < 200; i++ {
+		assert.Equal(t, 1, seen[fmt.Sprintf("stable-%03d", i)])
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test a shadow cache collects its own stats without changing the primary
+func TestAttachShadow(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.FIFO,
+		MaxSize:        2,
+		Metrics:        true,
+	})
+	defer c.Close()
+
+	assert.Nil(t, c.ShadowMetrics())
+	c.AttachShadow(cache.Config{EvictionPolicy: cache.LRU, MaxSize: 2})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("A")
+	c.Set("C", "Item C")
+
+	// FIFO evicted A, the LRU shadow evicted B
+	_, found := c.Get("A")
+	assert.False(t, found)
+	_, found = c.Get("B")
+	assert.True(t, found)
+
+	assert.Equal(t, int64(1), c.Metrics().Misses())
+
+	shadow := c.ShadowMetrics()
+	assert.NotNil(t, shadow)
+	assert.Equal(t, int64(1), shadow.Misses())
+	assert.Equal(t, int64(1), shadow.Evictions())
+	assert.Equal(t, c.Metrics().Evictions(), shadow.Evictions())
+
+	c.Delete("B")
+	_, found = c.Get("B")
+	assert.False(t, found)
+	assert.Equal(t, int64(2), shadow.Misses())
+
+	c.DetachShadow()
+	assert.Nil(t, c.ShadowMetrics())
+
+	c.Get("B")
+	assert.Equal(t, int64(2), shadow.Misses())
+}
+
+// Test `DeleteE()` is mirrored into the shadow cache like Delete
+func TestShadowDeleteE(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	defer c.Close()
+
+	c.AttachShadow(cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 10})
+
+	c.Set("A", "Item A")
+	assert.NoError(t, c.DeleteE("A"))
+
+	_, found := c.Get("A")
+	assert.False(t, found)
+	assert.Equal(t, int64(1), c.ShadowMetrics().Misses())
+}
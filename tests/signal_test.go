@@ -0,0 +1,36 @@
+//go:build !windows
+
+package tests
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `WatchSignal()` clears the cache on the signal until `Close()`
+func TestWatchSignal(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	c.WatchSignal(syscall.SIGHUP)
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// The cache keeps working, and is cleared again on the next signal
+	c.Set("C", "Item C")
+	assert.True(t, c.Has("C"))
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	c.Close()
+}
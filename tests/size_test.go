@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `MaxKeyBytes` and `MaxValueBytes` reject oversized entries
+func TestMaxEntrySize(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		MaxKeyBytes:    8,
+		MaxValueBytes:  16,
+	})
+
+	c.Set("A", "small value")
+	assert.True(t, c.Has("A"))
+
+	c.Set(strings.Repeat("k", 9), "value")
+	assert.False(t, c.Has(strings.Repeat("k", 9)))
+
+	c.Set("B", strings.Repeat("v", 17))
+	assert.False(t, c.Has("B"))
+
+	assert.False(t, c.SetIfAbsent("C", strings.Repeat("v", 17)))
+	assert.ErrorIs(t, c.SetE("D", strings.Repeat("v", 17)), cache.ErrTooLarge)
+	assert.NoError(t, c.SetE("D", strings.Repeat("v", 16)))
+
+	assert.Equal(t, uint64(4), c.RejectedSets())
+	assert.ElementsMatch(t, []string{"A", "D"}, c.Keys())
+}
+
+// Test `MaxValueBytes` uses the configured `Sizer`
+func TestMaxValueBytesSizer(t *testing.T) {
+	type payload struct{ data []int }
+
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        10,
+		MaxValueBytes:  10,
+		Sizer: func(value any) int {
+			if p, ok := value.(payload); ok {
+				return len(p.data) * 8
+			}
+			return cache.DefaultSizer(value)
+		},
+	})
+
+	c.SetE("small", payload{data: []int{1}})
+	c.SetE("large", payload{data: []int{1, 2}})
+
+	assert.True(t, c.Has("small"))
+	assert.False(t, c.Has("large"))
+	assert.Equal(t, uint64(1), c.RejectedSets())
+}
+
+// Test `EstimatedBytes()` tracks the size of the stored entries
+func TestEstimatedBytes(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		MaxSize:         3,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+		EstimateSize:    true,
+	})
+	defer c.Close()
+
+	c.Set("A", strings.Repeat("a", 100))
+	c.Set("B", strings.Repeat("b", 50))
+	assert.Equal(t, uint64(1+100+1+50), c.EstimatedBytes())
+
+	// Overwriting replaces the size of the previous value
+	c.Set("A", strings.Repeat("a", 10))
+	assert.Equal(t, uint64(1+10+1+50), c.EstimatedBytes())
+
+	c.Delete("B")
+	assert.Equal(t, uint64(1+10), c.EstimatedBytes())
+
+	// Evicted and expired entries are no longer counted
+	c.SetWithDeadline("C", strings.Repeat("c", 20), clock.Now().Add(time.Hour))
+	c.SetWithDeadline("D", strings.Repeat("d", 20), clock.Now().Add(time.Hour))
+	c.SetWithDeadline("E", strings.Repeat("e", 20), clock.Now().Add(time.Hour))
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, uint64(3*21), c.EstimatedBytes())
+
+	c.Set("F", "f")
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, uint64(2*21), c.EstimatedBytes())
+
+	c.Clear()
+	assert.Zero(t, c.EstimatedBytes())
+}
+
+// Test `EstimatedBytes()` drives the memory limit when `EstimateSize` is set
+func TestEstimatedBytesMemoryLimit(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:      cache.FIFO,
+		EstimateSize:        true,
+		MemoryLimits:        100,
+		MemoryCheckInterval: 10 * time.Millisecond,
+	})
+	defer c.Close()
+
+	for _, key := range []string{"A", "B", "C", "D"} {
+		c.Set(key, strings.Repeat("v", 39))
+	}
+
+	assert.Eventually(t, func() bool {
+		return c.EstimatedBytes() <= 100
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, c.Has("A"))
+}
+
+// Test `SizeHistogram()` counts the values per size bucket
+func TestSizeHistogram(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 100, Compress: true})
+
+	assert.Equal(t, map[string]int{
+		"<1KB": 0, "1KB-10KB": 0, "10KB-100KB": 0, "100KB-1MB": 0, "1MB-10MB": 0, ">=10MB": 0,
+	}, c.SizeHistogram())
+
+	sizes := map[string]int{
+		"tiny-1":  10,
+		"tiny-2":  1023,
+		"small":   1024,
+		"medium":  50 << 10,
+		"large-1": 200 << 10,
+		"large-2": 1<<20 - 1,
+		"huge":    2 << 20,
+	}
+	for key, size := range sizes {
+		c.SetBytes(key, bytes.Repeat([]byte("x"), size))
+	}
+
+	// Values are measured before compression
+	assert.Equal(t, map[string]int{
+		"<1KB": 2, "1KB-10KB": 1, "10KB-100KB": 1, "100KB-1MB": 2, "1MB-10MB": 1, ">=10MB": 0,
+	}, c.SizeHistogram())
+}
+
+// Test `SizeHistogram()` measures values with the configured `Sizer`
+func TestSizeHistogramSizer(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        100,
+		Sizer:          func(value any) int { return value.(int) },
+	})
+
+	c.Set("A", 100)
+	c.Set("B", 20<<20)
+
+	histogram := c.SizeHistogram()
+	assert.Equal(t, 1, histogram["<1KB"])
+	assert.Equal(t, 1, histogram[">=10MB"])
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `ReadOnlySnapshot()` is not affected by later changes to the cache
+func TestReadOnlySnapshot(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 3, TTL: time.Minute})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			c.Set("B", "Item B")
+
+			snapshot := c.ReadOnlySnapshot()
+
+			c.Set("A", "Item A2")
+			c.Delete("B")
+			c.Set("C", "Item C")
+			c.Set("D", "Item D")
+			c.Clear()
+
+			assert.Equal(t, 2, snapshot.Len())
+			val, found := snapshot.Get("A")
+			assert.True(t, found)
+			assert.Equal(t, "Item A", val)
+			val, found = snapshot.Get("B")
+			assert.True(t, found)
+			assert.Equal(t, "Item B", val)
+			_, found = snapshot.Get("C")
+			assert.False(t, found)
+
+			visited := make(map[string]any)
+			snapshot.Range(func(key string, value any) bool {
+				visited[key] = value
+				return true
+			})
+			assert.Equal(t, map[string]any{"A": "Item A", "B": "Item B"}, visited)
+		})
+	}
+}
+
+// Test a snapshot can be read concurrently with writes to the cache
+func TestReadOnlySnapshotConcurrent(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 100})
+	for i := 0; i < 50; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	snapshot := c.ReadOnlySnapshot()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.Set(fmt.Sprintf("key-%d", i%100), -i)
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				assert.Equal(t, 50, snapshot.Len())
+				val, found := snapshot.Get(fmt.Sprintf("key-%d", j%50))
+				assert.True(t, found)
+				assert.Equal(t, j%50, val)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Test `Entries()` returns the inserted entries
+func TestEntries(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10})
+			defer c.Close()
+
+			assert.Empty(t, c.Entries())
+
+			want := map[string]any{"A": "Item A", "B": 2, "C": []byte("Item C")}
+			for key, value := range want {
+				c.Set(key, value)
+			}
+			entries := c.Entries()
+			assert.Equal(t, want, entries)
+
+			// The map is a copy: later changes on either side don't show on the other
+			c.Delete("A")
+			entries["D"] = "Item D"
+			assert.Equal(t, "Item A", entries["A"])
+			assert.Equal(t, map[string]any{"B": 2, "C": []byte("Item C")}, c.Entries())
+		})
+	}
+}
+
+// Test `Entries()` excludes expired entries
+func TestEntriesExpired(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Basic, TTL: time.Minute, CleanupInterval: time.Hour, Clock: clock.Now})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	clock.Advance(30 * time.Second)
+	c.Set("B", "Item B")
+	clock.Advance(45 * time.Second)
+
+	assert.Equal(t, map[string]any{"B": "Item B"}, c.Entries())
+}
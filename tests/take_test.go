@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+var allPolicies = []cache.EvictionPolicy{cache.Basic, cache.FIFO, cache.LRU, cache.LFU, cache.LRUK, cache.Clock}
+
+// Test `GetAndDelete()` across policies
+func TestGetAndDelete(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        3,
+				TTL:            time.Minute,
+			})
+
+			c.Set("A", "Item A")
+			c.Set("B", "Item B")
+
+			val, found := c.GetAndDelete("A")
+			assert.True(t, found)
+			assert.Equal(t, "Item A", val)
+
+			assert.False(t, c.Has("A"))
+			assert.Equal(t, 1, c.Len())
+
+			_, found = c.GetAndDelete("A")
+			assert.False(t, found)
+
+			// Auxiliary structures stay consistent after the removal
+			c.Set("C", "Item C")
+			c.Set("D", "Item D")
+			assert.Equal(t, 3, c.Len())
+			assert.ElementsMatch(t, []string{"B", "C", "D"}, c.Keys())
+		})
+	}
+}
+
+// Test concurrent `GetAndDelete()` on the same key
+func TestGetAndDeleteConcurrent(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LFU,
+		MaxSize:        10,
+	})
+	c.Set("token", "secret")
+
+	var received atomic.Int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, found := c.GetAndDelete("token"); found {
+				received.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), received.Load())
+}
+
+// Test a stored nil value is distinct from a missing key
+func TestNilValue(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+			})
+
+			c.Set("nil", nil)
+
+			val, found := c.Get("nil")
+			assert.True(t, found)
+			assert.Nil(t, val)
+			assert.True(t, c.Has("nil"))
+
+			_, found = c.Get("missing")
+			assert.False(t, found)
+
+			val, err := c.GetE("nil")
+			assert.NoError(t, err)
+			assert.Nil(t, val)
+
+			val, found = c.GetAndDelete("nil")
+			assert.True(t, found)
+			assert.Nil(t, val)
+			assert.False(t, c.Has("nil"))
+		})
+	}
+}
+
+// Test `DeleteFunc()` removes entries matching a value predicate
+func TestDeleteFunc(t *testing.T) {
+	type response struct{ status int }
+
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+			})
+
+			c.Set("A", response{status: 200})
+			c.Set("B", response{status: 500})
+			c.Set("C", response{status: 200})
+			c.Set("D", response{status: 500})
+
+			removed := c.DeleteFunc(func(_ string, value any) bool {
+				return value.(response).status == 500
+			})
+
+			assert.Equal(t, 2, removed)
+			assert.ElementsMatch(t, []string{"A", "C"}, c.Keys())
+			assert.Equal(t, 2, c.Len())
+
+			// Auxiliary structures are updated: the remaining keys still evict
+			c.Evict()
+			c.Evict()
+			assert.Equal(t, 0, c.Len())
+		})
+	}
+}
+
+// Test `Drain()` returns every entry and leaves the cache empty
+func TestDrain(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+			})
+			defer c.Close()
+
+			want := map[string]any{"A": "Item A", "B": "Item B", "C": nil}
+			for key, value := range want {
+				c.Set(key, value)
+			}
+
+			assert.Equal(t, want, c.Drain())
+			assert.Equal(t, 0, c.Len())
+			assert.Empty(t, c.Keys())
+			assert.Empty(t, c.Drain())
+
+			c.Set("D", "Item D")
+			assert.Equal(t, map[string]any{"D": "Item D"}, c.Drain())
+		})
+	}
+}
+
+// Test `Drain()` skips expired entries
+func TestDrainSkipsExpired(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				TTL:            time.Minute,
+				Clock:          clock.Now,
+			})
+			defer c.Close()
+
+			c.Set("old", "Item old")
+			clock.Advance(30 * time.Second)
+			c.Set("new", "Item new")
+			clock.Advance(45 * time.Second)
+
+			assert.Equal(t, map[string]any{"new": "Item new"}, c.Drain())
+			assert.Equal(t, 0, c.Len())
+		})
+	}
+}
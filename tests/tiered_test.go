@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/basic"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/hugocarreira/easycache/lru"
+	"github.com/hugocarreira/easycache/tiered"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// TieredTestSuite defines the test structure
+type TieredTestSuite struct {
+	suite.Suite
+	l1 engine.Engine
+	l2 engine.Engine
+}
+
+// Setup before each test
+func (suite *TieredTestSuite) SetupTest() {
+	suite.l1 = lru.New(2)
+	suite.l2 = basic.New(4, time.Minute, time.Minute)
+}
+
+// Test promotion on L1 miss / L2 hit
+func (suite *TieredTestSuite) TestPromotion() {
+	c := tiered.New(suite.l1, suite.l2, tiered.Options{L1Size: 2, L2Size: 4})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Set("C", "Item C")
+
+	assert.False(suite.T(), suite.l1.Has("A"))
+	assert.True(suite.T(), suite.l2.Has("A"))
+
+	val, found := c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+	assert.True(suite.T(), suite.l1.Has("A"))
+
+	assert.Equal(suite.T(), 2, suite.l1.Len())
+	assert.Equal(suite.T(), 3, suite.l2.Len())
+	assert.Equal(suite.T(), 3, c.Len())
+}
+
+// yieldingEngine yields to other goroutines in Len, widening the window
+// between the size check and the insertion of a promotion
+type yieldingEngine struct {
+	engine.Engine
+}
+
+func (e yieldingEngine) Len() int {
+	n := e.Engine.Len()
+	runtime.Gosched()
+	return n
+}
+
+// Test concurrent promotions never overfill L1
+func (suite *TieredTestSuite) TestConcurrentPromotion() {
+	l1 := yieldingEngine{lru.New(0)}
+	c := tiered.New(l1, lru.New(0), tiered.Options{L1Size: 4, WritePolicy: tiered.WriteL2})
+
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				c.Get(fmt.Sprintf("key-%d", (i+g*13)%100))
+				assert.LessOrEqual(suite.T(), l1.Len(), 4)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(suite.T(), l1.Len(), 4)
+	assert.Equal(suite.T(), 100, c.Len())
+}
+
+// Test each level is sized independently
+func (suite *TieredTestSuite) TestIndependentSizing() {
+	c := tiered.New(suite.l1, suite.l2, tiered.Options{L1Size: 2, L2Size: 4})
+
+	for _, key := range []string{"A", "B", "C", "D", "E", "F"} {
+		c.Set(key, "value")
+	}
+
+	assert.Equal(suite.T(), 2, suite.l1.Len())
+	assert.Equal(suite.T(), 4, suite.l2.Len())
+}
+
+// Test demotion of items evicted from L1
+func (suite *TieredTestSuite) TestDemoteOnEvict() {
+	c := tiered.New(suite.l1, suite.l2, tiered.Options{
+		L1Size:        2,
+		L2Size:        4,
+		WritePolicy:   tiered.WriteL1,
+		DemoteOnEvict: true,
+	})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	assert.Equal(suite.T(), 0, suite.l2.Len())
+
+	c.Set("C", "Item C")
+	assert.False(suite.T(), suite.l1.Has("A"))
+	assert.True(suite.T(), suite.l2.Has("A"))
+
+	val, found := c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+}
+
+// Run the test suite
+// Test `Len()` counts keys stored in both levels once
+func (suite *TieredTestSuite) TestLenDistinctKeys() {
+	c := tiered.New(suite.l1, suite.l2, tiered.Options{WritePolicy: tiered.WriteL2})
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+	c.Get("A")
+	assert.Equal(suite.T(), 2, c.Len())
+
+	// A key left only in L1 is still counted
+	suite.l2.Delete("A")
+	assert.Equal(suite.T(), 2, c.Len())
+
+	suite.l1.Set("C", "Item C")
+	assert.Equal(suite.T(), 3, c.Len())
+	assert.Len(suite.T(), c.Keys(), c.Len())
+}
+
+func TestTieredTestSuite(t *testing.T) {
+	suite.Run(t, new(TieredTestSuite))
+}
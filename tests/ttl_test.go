@@ -0,0 +1,892 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/basic"
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/hugocarreira/easycache/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// TTLTestSuite defines the test structure
+type TTLTestSuite struct {
+	suite.Suite
+	c *cache.Cache
+}
+
+// Setup before each test
+func (suite *TTLTestSuite) SetupTest() {
+	suite.c = cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             50 * time.Millisecond,
+		CleanupInterval: time.Minute,
+	})
+}
+
+// Test `Touch()` extends the lifetime of a key
+func (suite *TTLTestSuite) TestTouchExtends() {
+	suite.c.Set("A", "Item A")
+
+	assert.True(suite.T(), suite.c.Touch("A", time.Minute))
+
+	time.Sleep(100 * time.Millisecond)
+
+	val, found := suite.c.Get("A")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "Item A", val)
+}
+
+// Test `Touch()` shortens the lifetime of a key
+func (suite *TTLTestSuite) TestTouchShortens() {
+	suite.c.Set("A", "Item A")
+
+	assert.True(suite.T(), suite.c.Touch("A", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	assert.False(suite.T(), suite.c.Has("A"))
+	assert.False(suite.T(), suite.c.Touch("A", time.Minute))
+}
+
+// Test `Touch()` on missing keys and non-expirable policies
+func (suite *TTLTestSuite) TestTouchMissing() {
+	assert.False(suite.T(), suite.c.Touch("X", time.Minute))
+
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 2})
+	c.Set("A", "Item A")
+	assert.False(suite.T(), c.Touch("A", time.Minute))
+}
+
+// Run the test suite
+func TestTTLTestSuite(t *testing.T) {
+	suite.Run(t, new(TTLTestSuite))
+}
+
+// Test a zero TTL means the configured TTL in `Touch()` like in `SetNX()`
+func TestTouchZeroTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{time.Minute, 0} {
+		t.Run(ttl.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  cache.Basic,
+				TTL:             ttl,
+				CleanupInterval: time.Hour,
+				Clock:           clock.Now,
+			})
+			defer c.Close()
+
+			c.SetNX("nx", "value", 0)
+			c.SetWithDeadline("touched", "value", clock.Now().Add(time.Second))
+			assert.True(t, c.Touch("touched", 0))
+
+			clock.Advance(30 * time.Second)
+			assert.ElementsMatch(t, []string{"nx", "touched"}, c.Keys())
+
+			// Both expire with the configured TTL, or never without one
+			clock.Advance(time.Minute)
+			if ttl > 0 {
+				assert.Empty(t, c.Keys())
+			} else {
+				assert.ElementsMatch(t, []string{"nx", "touched"}, c.Keys())
+			}
+		})
+	}
+}
+
+// Test a TTL of 0 never expires items
+func TestZeroTTLNeverExpires(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             0,
+		CleanupInterval: 10 * time.Millisecond,
+	})
+
+	c.Set("A", "Item A")
+	time.Sleep(50 * time.Millisecond)
+
+	val, found := c.Get("A")
+	assert.True(t, found)
+	assert.Equal(t, "Item A", val)
+	assert.True(t, c.Has("A"))
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, []string{"A"}, c.Keys())
+	assert.NotContains(t, c.Dump(), "ttl=")
+}
+
+// Test `SetWithDeadline()` expires the item at the deadline
+func TestSetWithDeadline(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Hour,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+
+	deadline := clock.Now().Add(30 * time.Second)
+	c.SetWithDeadline("token", "jwt", deadline)
+
+	clock.Advance(30 * time.Second)
+	val, found := c.Get("token")
+	assert.True(t, found)
+	assert.Equal(t, "jwt", val)
+
+	clock.Advance(time.Nanosecond)
+	_, found = c.Get("token")
+	assert.False(t, found)
+}
+
+// Test `SetWithDeadline()` ignores the deadline on non-expirable policies
+func TestSetWithDeadlineNonExpirable(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy: cache.LRU,
+		MaxSize:        1,
+	})
+
+	c.SetWithDeadline("A", "Item A", time.Now().Add(-time.Hour))
+	c.SetWithDeadline("B", "Item B", time.Now().Add(-time.Hour))
+
+	val, found := c.Get("B")
+	assert.True(t, found)
+	assert.Equal(t, "Item B", val)
+	assert.False(t, c.Has("A"))
+}
+
+// Test Basic `Len()` and `LenExact()` both exclude expired items
+func TestBasicLenExact(t *testing.T) {
+	e := basic.New(0, 20*time.Millisecond, time.Hour).(*basic.Basic)
+	e.Set("A", "Item A")
+	e.SetWithTTL("B", "Item B", time.Now().Add(time.Hour))
+
+	time.Sleep(40 * time.Millisecond)
+
+	assert.Equal(t, 1, e.LenExact())
+	assert.Equal(t, 1, e.Len())
+}
+
+// Test `Has()`, `Get()` and `Len()` agree once a key expires, by TTL or idle time
+func TestLenConsistentWithHas(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		MaxIdle:         30 * time.Second,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("ttl", "Item ttl")
+	c.Set("idle", "Item idle")
+	assert.Equal(t, 2, c.Len())
+
+	// "idle" is idle for longer than MaxIdle while "ttl" is kept active
+	for i := 0; i < 4; i++ {
+		clock.Advance(10 * time.Second)
+		c.Get("ttl")
+	}
+	assert.False(t, c.Has("idle"))
+	assert.True(t, c.Has("ttl"))
+	assert.Equal(t, 1, c.Len())
+
+	clock.Advance(30 * time.Second)
+	_, found := c.Get("ttl")
+	assert.False(t, found)
+	assert.False(t, c.Has("ttl"))
+	assert.Equal(t, 0, c.Len())
+}
+
+// Test `CleanupBatchFraction` bounds the deletions of a single cleanup sweep
+func TestCleanupBatchFraction(t *testing.T) {
+	clock := newFakeClock()
+	e := basic.NewWithOptions(basic.Options{
+		TTL:                  time.Minute,
+		CleanupInterval:      time.Hour,
+		CleanupBatchFraction: 0.1,
+		Clock:                clock.Now,
+	}).(*basic.Basic)
+
+	for i := 0; i < 1000; i++ {
+		e.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+	clock.Advance(2 * time.Minute)
+
+	total := 0
+	for i := 0; i < 10; i++ {
+		removed := e.Cleanup()
+		assert.LessOrEqual(t, removed, 200)
+		total += removed
+	}
+
+	// Ten sweeps of a tenth of the keys cover the whole cache
+	assert.Equal(t, 1000, total)
+	assert.Equal(t, 0, e.Len())
+}
+
+// Test `Cleanup()` sweeps the whole cache without `CleanupBatchFraction`
+func TestCleanupFullSweep(t *testing.T) {
+	clock := newFakeClock()
+	e := basic.NewWithOptions(basic.Options{
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	}).(*basic.Basic)
+
+	for i := 0; i < 100; i++ {
+		e.Set(fmt.Sprintf("key-%d", i), "value")
+	}
+	clock.Advance(2 * time.Minute)
+
+	assert.Equal(t, 100, e.Cleanup())
+	assert.Equal(t, 0, e.Len())
+}
+
+// Test an expired read is deferred to the cleanup by default
+func TestExpiredReadDeferred(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  policy,
+				TTL:             time.Minute,
+				CleanupInterval: time.Hour,
+				Clock:           clock.Now,
+			})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			clock.Advance(2 * time.Minute)
+
+			events := c.Events()
+			_, found := c.Get("A")
+			assert.False(t, found)
+			assert.False(t, c.Has("A"))
+
+			// The read does not remove the item, the next sweep does
+			select {
+			case event := <-events:
+				assert.Fail(t, "unexpected event", event)
+			default:
+			}
+			assert.Equal(t, 0, c.Len())
+			assert.Equal(t, cache.Event{Key: "A", Reason: cache.ReasonExpired}, <-events)
+		})
+	}
+}
+
+// Test an expired read deletes inline with `LazyExpiryDelete`
+func TestExpiredReadInline(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:   policy,
+				TTL:              time.Minute,
+				CleanupInterval:  time.Hour,
+				Clock:            clock.Now,
+				LazyExpiryDelete: true,
+			})
+			defer c.Close()
+
+			events := c.Events()
+			c.Set("A", "Item A")
+			clock.Advance(2 * time.Minute)
+
+			_, found := c.Get("A")
+			assert.False(t, found)
+			assert.Equal(t, cache.Event{Key: "A", Reason: cache.ReasonExpired}, <-events)
+			assert.Equal(t, 0, c.Len())
+		})
+	}
+}
+
+// Test concurrent reads of an expired key delete it once with `LazyExpiryDelete`
+func TestExpiredReadInlineConcurrent(t *testing.T) {
+	configs := map[string]func(cfg *cache.Config){
+		"basic": func(cfg *cache.Config) { cfg.EvictionPolicy = cache.Basic },
+		"fifo":  func(cfg *cache.Config) { cfg.EvictionPolicy = cache.FIFO },
+		"custom": func(cfg *cache.Config) {
+			cfg.CustomEngine = func(int) engine.Engine {
+				return &expiringEngine{lifoEngine: newLIFOEngine(), expired: map[string]bool{"A": true}}
+			}
+		},
+	}
+
+	for name, configure := range configs {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			cfg := &cache.Config{
+				MaxSize:          10,
+				CleanupInterval:  time.Hour,
+				Clock:            clock.Now,
+				LazyExpiryDelete: true,
+				EstimateSize:     true,
+			}
+			configure(cfg)
+			c := cache.New(cfg)
+			defer c.Close()
+
+			events := c.Events()
+			c.Set("B", "Item B")
+			expected := c.EstimatedBytes()
+			c.SetWithDeadline("A", "Item A", clock.Now().Add(time.Minute))
+			clock.Advance(2 * time.Minute)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, found := c.Get("A")
+					assert.False(t, found)
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, cache.Event{Key: "A", Reason: cache.ReasonExpired}, <-events)
+			select {
+			case event := <-events:
+				assert.Fail(t, "unexpected event", event)
+			default:
+			}
+			assert.Equal(t, expected, c.EstimatedBytes())
+			assert.Equal(t, 1, c.Len())
+		})
+	}
+}
+
+// Test `SetManyWithTTL()` expires each item after its own TTL
+func TestSetManyWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Hour,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.SetManyWithTTL(map[string]cache.ItemWithTTL{
+		"short":   {Value: "Item short", TTL: time.Second},
+		"medium":  {Value: "Item medium", TTL: time.Minute},
+		"default": {Value: "Item default"},
+	})
+	assert.Equal(t, 3, c.Len())
+
+	clock.Advance(2 * time.Second)
+	assert.False(t, c.Has("short"))
+	assert.True(t, c.Has("medium"))
+	assert.True(t, c.Has("default"))
+
+	clock.Advance(2 * time.Minute)
+	assert.False(t, c.Has("medium"))
+	assert.True(t, c.Has("default"))
+
+	clock.Advance(time.Hour)
+	assert.False(t, c.Has("default"))
+}
+
+// Test `MaxSize` is enforced on the Basic policy
+func TestBasicMaxSize(t *testing.T) {
+	clock := newFakeClock()
+	clock.SetStep(time.Millisecond)
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		MaxSize:         3,
+		TTL:             time.Minute,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+		Metrics:         true,
+	})
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), "value")
+		assert.LessOrEqual(t, c.Len(), 3)
+	}
+
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, int64(7), c.Metrics().Evictions())
+
+	// The items closest to expiring were evicted
+	assert.ElementsMatch(t, []string{"key-7", "key-8", "key-9"}, c.Keys())
+
+	// Updating an existing key doesn't evict
+	c.Set("key-9", "updated")
+	assert.Equal(t, int64(7), c.Metrics().Evictions())
+}
+
+// Test Basic evicts by deadline, then never-expiring items in insertion order
+func TestBasicEvictOrder(t *testing.T) {
+	clock := newFakeClock()
+	e := basic.NewWithOptions(basic.Options{
+		CleanupInterval: time.Hour,
+		MaxIdle:         10 * time.Minute,
+		Clock:           clock.Now,
+	})
+	defer e.(*basic.Basic).Close()
+
+	now := clock.Now()
+	e.SetWithTTL("A", "Item A", now.Add(time.Hour))
+	e.SetWithTTL("B", "Item B", now.Add(7*time.Minute))
+	e.SetWithTTL("C", "Item C", now.Add(7*time.Minute))
+	e.SetWithTTL("D", "Item D", now.Add(time.Hour))
+
+	// A read postpones the idle deadline of D past the one of A
+	clock.Advance(5 * time.Minute)
+	e.Get("D")
+	assert.Equal(t, []string{"B", "C", "A", "D"}, e.Keys())
+
+	for _, expected := range []string{"B", "C", "A", "D"} {
+		key, _, ok := e.Evict()
+		assert.True(t, ok)
+		assert.Equal(t, expected, key)
+	}
+
+	eternal := basic.NewWithOptions(basic.Options{CleanupInterval: time.Hour, Clock: clock.Now})
+	defer eternal.(*basic.Basic).Close()
+
+	eternal.Set("X", "Item X")
+	eternal.SetWithTTL("Y", "Item Y", clock.Now().Add(time.Hour))
+	eternal.Set("Z", "Item Z")
+	assert.Equal(t, []string{"Y", "X", "Z"}, eternal.Keys())
+
+	for _, expected := range []string{"Y", "X", "Z"} {
+		key, _, ok := eternal.Evict()
+		assert.True(t, ok)
+		assert.Equal(t, expected, key)
+	}
+	_, _, ok := eternal.Evict()
+	assert.False(t, ok)
+}
+
+// Test `MaxIdle` reclaims idle items while active ones survive
+func TestMaxIdle(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Hour,
+		MaxIdle:         time.Minute,
+		CleanupInterval: 10 * time.Millisecond,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("active", "value")
+	c.Set("idle", "value")
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(30 * time.Second)
+		_, found := c.Get("active")
+		assert.True(t, found)
+	}
+
+	assert.False(t, c.Has("idle"))
+
+	// The sweeper removes the idle item
+	assert.Eventually(t, func() bool {
+		return c.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// The absolute TTL still applies to the active item
+	found := true
+	for i := 0; i < 120 && found; i++ {
+		clock.Advance(30 * time.Second)
+		_, found = c.Get("active")
+	}
+	assert.False(t, found)
+}
+
+// Test `AutoShrink` rebuilds the Basic key index once the cache drains
+func TestBasicAutoShrink(t *testing.T) {
+	for _, autoShrink := range []bool{false, true} {
+		t.Run(fmt.Sprintf("AutoShrink=%v", autoShrink), func(t *testing.T) {
+			clock := newFakeClock()
+			e := basic.NewWithOptions(basic.Options{
+				CleanupInterval:      time.Hour,
+				CleanupBatchFraction: 0.5,
+				Clock:                clock.Now,
+				AutoShrink:           autoShrink,
+			}).(*basic.Basic)
+			defer e.Close()
+
+			for i := 0; i < 10000; i++ {
+				e.SetWithTTL(fmt.Sprintf("key-%d", i), i, clock.Now().Add(time.Minute))
+			}
+			for i := 0; i < 100; i++ {
+				e.SetWithTTL(fmt.Sprintf("live-%d", i), i, time.Time{})
+			}
+			assert.Equal(t, 10100, e.Capacity())
+
+			clock.Advance(2 * time.Minute)
+			e.Cleanup()
+			e.Cleanup()
+
+			assert.Equal(t, 100, e.Len())
+			if autoShrink {
+				assert.Equal(t, 100, e.Capacity())
+			} else {
+				assert.Equal(t, 10100, e.Capacity())
+			}
+
+			val, found := e.Get("live-42")
+			assert.True(t, found)
+			assert.Equal(t, 42, val)
+		})
+	}
+}
+
+// Test `GetStale()` serves expired values within `StaleGrace`
+func TestGetStale(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Minute,
+		StaleGrace:      30 * time.Second,
+		CleanupInterval: time.Hour,
+		Metrics:         true,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+
+	val, stale, ok := c.GetStale("A")
+	assert.True(t, ok)
+	assert.False(t, stale)
+	assert.Equal(t, "Item A", val)
+
+	// Past the TTL, within the grace period: stale for GetStale, a miss for Get
+	clock.Advance(time.Minute + 10*time.Second)
+	val, stale, ok = c.GetStale("A")
+	assert.True(t, ok)
+	assert.True(t, stale)
+	assert.Equal(t, "Item A", val)
+
+	_, found := c.Get("A")
+	assert.False(t, found)
+	assert.False(t, c.Has("A"))
+	assert.Equal(t, 0, c.Len())
+
+	// Still served once the expired item has been removed
+	val, stale, ok = c.GetStale("A")
+	assert.True(t, ok)
+	assert.True(t, stale)
+	assert.Equal(t, "Item A", val)
+
+	// Refreshing the value makes it fresh again
+	c.Set("A", "Item A2")
+	val, stale, ok = c.GetStale("A")
+	assert.True(t, ok)
+	assert.False(t, stale)
+	assert.Equal(t, "Item A2", val)
+
+	// Past the grace period, it is gone
+	clock.Advance(2 * time.Minute)
+	_, _, ok = c.GetStale("A")
+	assert.False(t, ok)
+
+	_, _, ok = c.GetStale("missing")
+	assert.False(t, ok)
+}
+
+// Test `GetStale()` without `StaleGrace` behaves like `Get()`
+func TestGetStaleWithoutGrace(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.LRU} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy: policy,
+				MaxSize:        10,
+				TTL:            time.Minute,
+				Clock:          clock.Now,
+			})
+			defer c.Close()
+
+			c.Set("A", "Item A")
+			val, stale, ok := c.GetStale("A")
+			assert.True(t, ok)
+			assert.False(t, stale)
+			assert.Equal(t, "Item A", val)
+
+			if policy == cache.Basic {
+				clock.Advance(2 * time.Minute)
+				_, _, ok = c.GetStale("A")
+				assert.False(t, ok)
+			}
+		})
+	}
+}
+
+// Test `SetWithExpireCallback()` calls the callback once on expiration
+func TestSetWithExpireCallback(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  policy,
+				TTL:             time.Hour,
+				CleanupInterval: 10 * time.Millisecond,
+				Clock:           clock.Now,
+			})
+			defer c.Close()
+
+			var (
+				lock  sync.Mutex
+				calls []any
+			)
+			record := func(value any) {
+				lock.Lock()
+				defer lock.Unlock()
+				calls = append(calls, value)
+			}
+			count := func() int {
+				lock.Lock()
+				defer lock.Unlock()
+				return len(calls)
+			}
+
+			c.SetWithExpireCallback("A", "Item A", time.Minute, record)
+			c.SetWithExpireCallback("B", "Item B", time.Minute, record)
+			c.Set("C", "Item C")
+
+			val, found := c.Get("A")
+			assert.True(t, found)
+			assert.Equal(t, "Item A", val)
+
+			// Deleting an entry doesn't call its callback
+			c.Delete("B")
+
+			clock.Advance(2 * time.Minute)
+			_, found = c.Get("A")
+			assert.False(t, found)
+			assert.Equal(t, 1, c.Len())
+
+			// The sweep runs again on every tick, but the callback only once
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, 1, count())
+			assert.Equal(t, []any{"Item A"}, calls)
+		})
+	}
+}
+
+// Test `SetWithExpireCallback()` calls the callback on eviction
+func TestSetWithExpireCallbackEvicted(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 1})
+
+	var evicted []any
+	c.SetWithExpireCallback("A", "Item A", time.Minute, func(value any) {
+		evicted = append(evicted, value)
+	})
+	c.Set("B", "Item B")
+
+	assert.Equal(t, []any{"Item A"}, evicted)
+	assert.False(t, c.Has("A"))
+}
+
+// Test the cleanup interval adapts to expirations within its bounds
+func TestAdaptiveCleanupInterval(t *testing.T) {
+	clock := newFakeClock()
+	e := basic.NewWithOptions(basic.Options{
+		TTL:                time.Minute,
+		CleanupInterval:    10 * time.Second,
+		MinCleanupInterval: time.Second,
+		MaxCleanupInterval: 80 * time.Second,
+		Clock:              clock.Now,
+	}).(*basic.Basic)
+	defer e.Close()
+
+	assert.Equal(t, 10*time.Second, e.CleanupInterval())
+
+	// Idle sweeps lengthen the interval up to the max
+	for _, want := range []time.Duration{20, 40, 80, 80} {
+		e.Cleanup()
+		assert.Equal(t, want*time.Second, e.CleanupInterval())
+	}
+
+	// Bursts of expirations shorten it down to the min
+	bursts := []time.Duration{
+		40 * time.Second, 20 * time.Second, 10 * time.Second, 5 * time.Second,
+		2500 * time.Millisecond, 1250 * time.Millisecond, time.Second, time.Second,
+	}
+	for _, want := range bursts {
+		for i := 0; i < 100; i++ {
+			e.Set(fmt.Sprintf("key-%d", i), "value")
+		}
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, 100, e.Cleanup())
+		assert.Equal(t, want, e.CleanupInterval())
+	}
+
+	// A few expirations among many live items keep the interval
+	for i := 0; i < 100; i++ {
+		e.SetWithTTL(fmt.Sprintf("key-%d", i), "value", clock.Now().Add(time.Hour))
+	}
+	e.SetWithTTL("short", "value", clock.Now().Add(time.Second))
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, 1, e.Cleanup())
+	assert.Equal(t, time.Second, e.CleanupInterval())
+}
+
+// Test the cleanup interval is fixed without bounds
+func TestFixedCleanupInterval(t *testing.T) {
+	e := basic.NewWithOptions(basic.Options{TTL: time.Minute, CleanupInterval: 10 * time.Second}).(*basic.Basic)
+	defer e.Close()
+
+	e.Cleanup()
+	assert.Equal(t, 10*time.Second, e.CleanupInterval())
+}
+
+// Test `SetNX()` lets exactly one contender acquire a lock until its TTL
+func TestSetNX(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10, TTL: time.Hour, Clock: clock.Now})
+			defer c.Close()
+
+			var wg sync.WaitGroup
+			acquired := make([]bool, 2)
+			for i := range acquired {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					acquired[i] = c.SetNX("lock", i, 10*time.Second)
+				}(i)
+			}
+			wg.Wait()
+
+			assert.NotEqual(t, acquired[0], acquired[1])
+			owner, found := c.Get("lock")
+			assert.True(t, found)
+			assert.True(t, acquired[owner.(int)])
+
+			assert.False(t, c.SetNX("lock", "other", 10*time.Second))
+
+			// The lock is released by its TTL, not the configured one
+			clock.Advance(11 * time.Second)
+			assert.True(t, c.SetNX("lock", "other", 10*time.Second))
+
+			val, _ := c.Get("lock")
+			assert.Equal(t, "other", val)
+
+			// And by Delete
+			c.Delete("lock")
+			assert.True(t, c.SetNX("lock", "again", 0))
+		})
+	}
+}
+
+// Test `Set()` on an existing key resets its TTL unless `PreserveTTLOnUpdate` is set
+func TestPreserveTTLOnUpdate(t *testing.T) {
+	for _, policy := range []cache.EvictionPolicy{cache.Basic, cache.FIFO} {
+		for _, preserve := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/preserve=%t", policy, preserve), func(t *testing.T) {
+				clock := newFakeClock()
+				c := cache.New(&cache.Config{
+					EvictionPolicy:      policy,
+					MaxSize:             10,
+					TTL:                 time.Minute,
+					CleanupInterval:     time.Hour,
+					PreserveTTLOnUpdate: preserve,
+					Clock:               clock.Now,
+				})
+				defer c.Close()
+
+				c.Set("A", "first")
+				clock.Advance(45 * time.Second)
+				c.Set("A", "second")
+				clock.Advance(30 * time.Second)
+
+				val, found := c.Get("A")
+				assert.Equal(t, !preserve, found)
+				if found {
+					assert.Equal(t, "second", val)
+				}
+
+				// Once expired, the key starts over with a full TTL
+				c.Set("A", "third")
+				clock.Advance(45 * time.Second)
+				assert.True(t, c.Has("A"))
+
+				// An explicit deadline always applies
+				c.SetWithDeadline("A", "fourth", clock.Now().Add(time.Hour))
+				clock.Advance(30 * time.Minute)
+				assert.True(t, c.Has("A"))
+			})
+		}
+	}
+}
+
+// Test `MinTTL` raises TTLs and deadlines below the floor
+func TestMinTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             time.Millisecond,
+		MinTTL:          time.Second,
+		CleanupInterval: time.Hour,
+		Clock:           clock.Now,
+	})
+	defer c.Close()
+
+	c.Set("configured", "value")
+	c.SetNX("nx", "value", time.Nanosecond)
+	c.SetWithDeadline("deadline", "value", clock.Now())
+	c.SetManyWithTTL(map[string]cache.ItemWithTTL{"many": {Value: "value", TTL: time.Millisecond}})
+	c.SetWithExpireCallback("callback", "value", time.Millisecond, func(any) {})
+	c.SetWithDeadline("never", "value", time.Time{})
+	c.SetNX("long", "value", time.Minute)
+
+	c.Set("touched", "value")
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, c.Touch("touched", 0))
+
+	// Everything survives at least MinTTL
+	clock.Advance(400 * time.Millisecond)
+	assert.Equal(t, 8, c.Len())
+
+	clock.Advance(200 * time.Millisecond)
+	assert.ElementsMatch(t, []string{"touched", "never", "long"}, c.Keys())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.ElementsMatch(t, []string{"never", "long"}, c.Keys())
+}
+
+// Test `Len()` excludes expired entries in every policy supporting expiration
+func TestLenExcludesExpired(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := newFakeClock()
+			c := cache.New(&cache.Config{
+				EvictionPolicy:  policy,
+				MaxSize:         10,
+				CleanupInterval: time.Hour,
+				Clock:           clock.Now,
+			})
+			defer c.Close()
+
+			c.SetWithDeadline("short", "value", clock.Now().Add(time.Second))
+			c.SetWithDeadline("long", "value", clock.Now().Add(time.Hour))
+			c.Set("forever", "value")
+			assert.Equal(t, 3, c.Len())
+
+			clock.Advance(time.Minute)
+			want := 3
+			if c.IsExpirable() {
+				want = 2
+			}
+			assert.Equal(t, want, c.Len())
+			assert.Len(t, c.Keys(), want)
+
+			// Counting doesn't change once the expired entry is gone
+			c.Delete("long")
+			assert.Equal(t, want-1, c.Len())
+			assert.Equal(t, want-1, c.Len())
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `WithLock()` applies multi-key updates atomically
+func TestWithLock(t *testing.T) {
+	for _, policy := range allPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := cache.New(&cache.Config{EvictionPolicy: policy, MaxSize: 10})
+			defer c.Close()
+
+			c.Set("A", 100)
+			c.Set("B", 0)
+			c.Set("C", "marker")
+
+			const transfers = 500
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < transfers; i++ {
+					c.WithLock(func(tx cache.Tx) {
+						a, _ := tx.Get("A")
+						b, _ := tx.Get("B")
+						tx.Set("A", a.(int)-1)
+						tx.Set("B", b.(int)+1)
+
+						// Move the marker between C and D
+						if _, found := tx.Get("C"); found {
+							tx.Delete("C")
+							tx.Set("D", "marker")
+						} else {
+							tx.Delete("D")
+							tx.Set("C", "marker")
+						}
+					})
+				}
+			}()
+
+			for i := 0; i < transfers; i++ {
+				c.WithLock(func(tx cache.Tx) {
+					a, _ := tx.Get("A")
+					b, _ := tx.Get("B")
+					assert.Equal(t, 100, a.(int)+b.(int))
+				})
+
+				keys := c.Keys()
+				assert.Len(t, keys, 3)
+				assert.NotSubset(t, keys, []string{"C", "D"})
+			}
+			wg.Wait()
+
+			a, _ := c.Get("A")
+			b, _ := c.Get("B")
+			assert.Equal(t, 100-transfers, a)
+			assert.Equal(t, transfers, b)
+		})
+	}
+}
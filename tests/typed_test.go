@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `GetTyped()` on hits, misses and values of another type
+func TestGetTyped(t *testing.T) {
+	clock := newFakeClock()
+	c := cache.New(&cache.Config{EvictionPolicy: cache.Basic, TTL: time.Minute, Clock: clock.Now})
+	defer c.Close()
+
+	c.Set("name", "Item A")
+	c.Set("count", 42)
+	c.Set("duration", time.Second)
+
+	name, ok := cache.GetTyped[string](c, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "Item A", name)
+
+	count, ok := cache.GetTyped[int](c, "count")
+	assert.True(t, ok)
+	assert.Equal(t, 42, count)
+
+	// Interface types match any value implementing them
+	stringer, ok := cache.GetTyped[fmt.Stringer](c, "duration")
+	assert.True(t, ok)
+	assert.Equal(t, "1s", stringer.String())
+
+	// Wrong type
+	count, ok = cache.GetTyped[int](c, "name")
+	assert.False(t, ok)
+	assert.Equal(t, 0, count)
+
+	// Miss
+	name, ok = cache.GetTyped[string](c, "missing")
+	assert.False(t, ok)
+	assert.Equal(t, "", name)
+
+	// Expired
+	clock.Advance(2 * time.Minute)
+	_, ok = cache.GetTyped[string](c, "name")
+	assert.False(t, ok)
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `SetVersioned()` and `GetVersioned()` increase the version on every write
+func TestVersioned(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	v1 := c.SetVersioned("A", "Item A")
+	assert.NotZero(t, v1)
+
+	val, version, ok := c.GetVersioned("A")
+	assert.True(t, ok)
+	assert.Equal(t, "Item A", val)
+	assert.Equal(t, v1, version)
+
+	// Plain writes bump the version too
+	c.Set("A", "Item A2")
+	_, v2, _ := c.GetVersioned("A")
+	assert.Greater(t, v2, v1)
+
+	// Versions are not reused after a key is deleted and stored again
+	c.Delete("A")
+	_, _, ok = c.GetVersioned("A")
+	assert.False(t, ok)
+	assert.Greater(t, c.SetVersioned("A", "Item A3"), v2)
+}
+
+// Test `SetIfVersion()` only writes on a matching version
+func TestSetIfVersion(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+
+	// Version 0 means absent
+	assert.True(t, c.SetIfVersion("A", "Item A", 0))
+	assert.False(t, c.SetIfVersion("A", "Item A2", 0))
+
+	_, version, _ := c.GetVersioned("A")
+	assert.False(t, c.SetIfVersion("A", "Item A2", version+1))
+	assert.True(t, c.SetIfVersion("A", "Item A2", version))
+	assert.False(t, c.SetIfVersion("A", "Item A3", version))
+
+	val, _, _ := c.GetVersioned("A")
+	assert.Equal(t, "Item A2", val)
+}
+
+// Test concurrent `SetIfVersion()` calls with the same version: exactly one wins
+func TestSetIfVersionConcurrent(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	version := c.SetVersioned("counter", 0)
+
+	var (
+		wg   sync.WaitGroup
+		wins atomic.Int32
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if c.SetIfVersion("counter", i, version) {
+				wins.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), wins.Load())
+
+	// Optimistic increments: retry on conflict until every one is applied
+	c.Set("counter", 0)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				val, version, _ := c.GetVersioned("counter")
+				if c.SetIfVersion("counter", val.(int)+1, version) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	val, _, _ := c.GetVersioned("counter")
+	assert.Equal(t, 50, val)
+}
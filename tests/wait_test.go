@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hugocarreira/easycache/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test `WaitEmpty()` returns once the last item is removed
+func TestWaitEmpty(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	defer c.Close()
+
+	assert.NoError(t, c.WaitEmpty(context.Background()))
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	done := make(chan error, 1)
+	go func() { done <- c.WaitEmpty(context.Background()) }()
+
+	c.Delete("A")
+	select {
+	case <-done:
+		t.Fatal("WaitEmpty returned with an item left")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.Delete("B")
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("WaitEmpty did not return after the last delete")
+	}
+}
+
+// Test `WaitEmpty()` returns once the last items expire
+func TestWaitEmptyExpiration(t *testing.T) {
+	c := cache.New(&cache.Config{
+		EvictionPolicy:  cache.Basic,
+		TTL:             20 * time.Millisecond,
+		CleanupInterval: 10 * time.Millisecond,
+	})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+	c.Set("B", "Item B")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, c.WaitEmpty(ctx))
+	assert.Equal(t, 0, c.Len())
+}
+
+// Test `WaitEmpty()` returns the context error once it is canceled
+func TestWaitEmptyCanceled(t *testing.T) {
+	c := cache.New(&cache.Config{EvictionPolicy: cache.LRU, MaxSize: 10})
+	defer c.Close()
+
+	c.Set("A", "Item A")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.WaitEmpty(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("WaitEmpty did not return after the cancellation")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.WaitEmpty(ctx), context.DeadlineExceeded)
+	assert.True(t, c.Has("A"))
+}
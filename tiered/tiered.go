@@ -0,0 +1,335 @@
+package tiered
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hugocarreira/easycache/engine"
+)
+
+// WritePolicy defines which levels a Set writes to.
+type WritePolicy int
+
+const (
+	// WriteBoth writes to L1 and L2 (write-through).
+	WriteBoth WritePolicy = iota
+
+	// WriteL1 writes only to L1. Combined with DemoteOnEvict, L2 acts as a
+	// victim cache holding the items evicted from L1.
+	WriteL1
+
+	// WriteL2 writes only to L2 (write-around). Items reach L1 when they are
+	// read from L2. A key already in L1 is updated in place.
+	WriteL2
+)
+
+// Options defines the sizing and write behavior of a Tiered cache.
+type Options struct {
+	// L1Size is the maximum number of items in L1. A value of 0 means no limit.
+	L1Size int
+
+	// L2Size is the maximum number of items in L2. A value of 0 means no limit.
+	L2Size int
+
+	// WritePolicy selects which levels Set writes to.
+	WritePolicy WritePolicy
+
+	// DemoteOnEvict moves items evicted from L1 (to make room) into L2.
+	DemoteOnEvict bool
+}
+
+// Tiered is a multi-level cache composing a small, fast L1 engine in front of
+// a larger L2 engine.
+//
+// Get checks L1 first, then L2; a hit in L2 promotes the item into L1. Each
+// level is sized independently and evicts according to its own policy.
+//
+// Items promoted into an expirable L1 keep the expiration time they had in
+// L2. Items promoted into a non-expirable L1 do not inherit their TTL.
+//
+// Operations spanning both levels run under a lock of their own, so a Tiered
+// can be shared across goroutines. Get takes it exclusively, since a hit in L2
+// promotes the item into L1: concurrent Gets never overfill L1, even when
+// Cache only holds its read lock.
+type Tiered struct {
+	l1   engine.Engine
+	l2   engine.Engine
+	opts Options
+	lock sync.RWMutex
+}
+
+var (
+	_ engine.Engine   = (*Tiered)(nil)
+	_ engine.Closer   = (*Tiered)(nil)
+	_ engine.Reserver = (*Tiered)(nil)
+)
+
+func New(l1, l2 engine.Engine, opts Options) engine.Engine {
+	return &Tiered{
+		l1:   l1,
+		l2:   l2,
+		opts: opts,
+	}
+}
+
+func (c *Tiered) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if value, exists := c.l1.Get(key); exists {
+		return value, true
+	}
+
+	value, exists := c.l2.Get(key)
+	if !exists {
+		return nil, false
+	}
+
+	_, expiresAt, _ := c.l2.Peek(key)
+	c.putL1WithTTL(key, value, expiresAt)
+
+	return value, true
+}
+
+func (c *Tiered) Peek(key string) (any, time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if value, expiresAt, exists := c.l1.Peek(key); exists {
+		return value, expiresAt, true
+	}
+
+	return c.l2.Peek(key)
+}
+
+func (c *Tiered) Set(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch c.opts.WritePolicy {
+	case WriteL1:
+		c.putL1(key, value)
+	case WriteL2:
+		c.putL2(key, value)
+		if c.l1.Has(key) {
+			c.l1.Set(key, value)
+		}
+	default:
+		c.putL1(key, value)
+		c.putL2(key, value)
+	}
+}
+
+func (c *Tiered) SetWithTTL(key string, value any, expiresAt time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch c.opts.WritePolicy {
+	case WriteL1:
+		c.putL1WithTTL(key, value, expiresAt)
+	case WriteL2:
+		c.putL2WithTTL(key, value, expiresAt)
+		if c.l1.Has(key) {
+			c.l1.SetWithTTL(key, value, expiresAt)
+		}
+	default:
+		c.putL1WithTTL(key, value, expiresAt)
+		c.putL2WithTTL(key, value, expiresAt)
+	}
+}
+
+func (c *Tiered) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l1.Delete(key)
+	c.l2.Delete(key)
+}
+
+func (c *Tiered) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.l1.Has(key) || c.l2.Has(key)
+}
+
+// Len returns the number of distinct keys across both levels: the items of
+// L2, plus the keys of L1 missing from L2. It only walks L1, which is meant
+// to be the small level.
+func (c *Tiered) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	n := c.l2.Len()
+	for _, key := range c.l1.Keys() {
+		if !c.l2.Has(key) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (c *Tiered) IsExpirable() bool {
+	return c.l1.IsExpirable() || c.l2.IsExpirable()
+}
+
+func (c *Tiered) IsExpired(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.l1.Has(key) {
+		return c.l1.IsExpired(key)
+	}
+
+	return c.l2.IsExpired(key)
+}
+
+func (c *Tiered) Touch(key string, expiresAt time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	touchedL1 := c.l1.Touch(key, expiresAt)
+	touchedL2 := c.l2.Touch(key, expiresAt)
+
+	return touchedL1 || touchedL2
+}
+
+// Evict removes an item chosen by the L2 policy from both levels. If L2 is
+// empty, the victim is chosen by the L1 policy instead.
+func (c *Tiered) Evict() (string, any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key, value, evicted := c.l2.Evict(); evicted {
+		c.l1.Delete(key)
+		return key, value, true
+	}
+
+	return c.l1.Evict()
+}
+
+// Keys returns the distinct keys across both levels, in the order Evict
+// would remove them: L2 keys first, then keys only present in L1.
+func (c *Tiered) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	seen := make(map[string]struct{})
+	keys := make([]string, 0)
+
+	for _, key := range append(c.l2.Keys(), c.l1.Keys()...) {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Range visits the items of L1, then the items of L2 that are not in L1.
+func (c *Tiered) Range(fn func(key string, value any) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	more := true
+	c.l1.Range(func(key string, value any) bool {
+		more = fn(key, value)
+		return more
+	})
+	if !more {
+		return
+	}
+
+	c.l2.Range(func(key string, value any) bool {
+		if c.l1.Has(key) {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+func (c *Tiered) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l1.Clear()
+	c.l2.Clear()
+}
+
+// Close closes the tiers that run background goroutines.
+func (c *Tiered) Close() {
+	for _, e := range []engine.Engine{c.l1, c.l2} {
+		if closer, ok := e.(engine.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// Reserve preallocates both levels for n items, capped at each level's size.
+func (c *Tiered) Reserve(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, level := range []struct {
+		engine engine.Engine
+		size   int
+	}{{c.l1, c.opts.L1Size}, {c.l2, c.opts.L2Size}} {
+		if reserver, ok := level.engine.(engine.Reserver); ok {
+			if level.size > 0 {
+				reserver.Reserve(min(n, level.size))
+			} else {
+				reserver.Reserve(n)
+			}
+		}
+	}
+}
+
+// putL1 stores an item in L1 with the engine's default expiration.
+func (c *Tiered) putL1(key string, value any) {
+	c.makeRoomL1(key)
+	c.l1.Set(key, value)
+}
+
+// putL1WithTTL stores an item in L1 with the given expiration time, which is
+// dropped if L1 is not expirable.
+func (c *Tiered) putL1WithTTL(key string, value any, expiresAt time.Time) {
+	c.makeRoomL1(key)
+	if c.l1.IsExpirable() {
+		c.l1.SetWithTTL(key, value, expiresAt)
+		return
+	}
+
+	c.l1.Set(key, value)
+}
+
+// makeRoomL1 evicts (and optionally demotes) an item from L1 if key is new
+// and L1 is full.
+func (c *Tiered) makeRoomL1(key string) {
+	if c.opts.L1Size > 0 && !c.l1.Has(key) && c.l1.Len() >= c.opts.L1Size {
+		if evictedKey, evictedValue, evicted := c.l1.Evict(); evicted && c.opts.DemoteOnEvict {
+			c.putL2(evictedKey, evictedValue)
+		}
+	}
+}
+
+// putL2 stores an item in L2 with the engine's default expiration.
+func (c *Tiered) putL2(key string, value any) {
+	c.makeRoomL2(key)
+	c.l2.Set(key, value)
+}
+
+// putL2WithTTL stores an item in L2 with the given expiration time.
+func (c *Tiered) putL2WithTTL(key string, value any, expiresAt time.Time) {
+	c.makeRoomL2(key)
+	c.l2.SetWithTTL(key, value, expiresAt)
+}
+
+// makeRoomL2 evicts an item from L2 if key is new and L2 is full.
+func (c *Tiered) makeRoomL2(key string) {
+	if c.opts.L2Size > 0 && !c.l2.Has(key) && c.l2.Len() >= c.opts.L2Size {
+		c.l2.Evict()
+	}
+}